@@ -0,0 +1,396 @@
+package base64
+
+import (
+	"bytes"
+	"encoding/ascii85"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// codecSizes are the sizes of the raw data of the sweeps, from a token to
+// a large response body. All throughputs are in raw bytes, so encoders
+// and decoders of different expansion compare directly.
+var codecSizes = []int{16, 256, 4 << 10, 64 << 10, 1 << 20}
+
+// encodings are the four base64 alphabets and padding modes.
+var encodings = []struct {
+	name string
+	enc  *base64.Encoding
+}{
+	{"Std", base64.StdEncoding},
+	{"URL", base64.URLEncoding},
+	{"RawStd", base64.RawStdEncoding},
+	{"RawURL", base64.RawURLEncoding},
+}
+
+// BenchmarkBase64Encode encodes with every alphabet into a reused buffer.
+func BenchmarkBase64Encode(b *testing.B) {
+	for _, e := range encodings {
+		b.Run(e.name, func(b *testing.B) {
+			benchutil.Sizes(b, codecSizes, func(b *testing.B, n int) {
+				src := benchutil.Bytes(n)
+				buf := make([]byte, 0, e.enc.EncodedLen(n))
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					buf = e.enc.AppendEncode(buf[:0], src)
+				}
+				benchutil.BytesSink.Store(buf)
+			})
+		})
+	}
+}
+
+// BenchmarkBase64Decode decodes with every alphabet into a reused buffer.
+func BenchmarkBase64Decode(b *testing.B) {
+	for _, e := range encodings {
+		b.Run(e.name, func(b *testing.B) {
+			benchutil.Sizes(b, codecSizes, func(b *testing.B, n int) {
+				src := e.enc.AppendEncode(nil, benchutil.Bytes(n))
+				buf := make([]byte, 0, n)
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					var err error
+					if buf, err = e.enc.AppendDecode(buf[:0], src); err != nil {
+						b.Fatal(err)
+					}
+				}
+				benchutil.BytesSink.Store(buf)
+			})
+		})
+	}
+}
+
+// BenchmarkBase64EncodeAPI compares the ways to call the encoder:
+// allocating a string per call, into a buffer of the right size, appended
+// to a reused buffer, and through the streaming encoder.
+func BenchmarkBase64EncodeAPI(b *testing.B) {
+	enc := base64.StdEncoding
+	variants := []struct {
+		name string
+		fn   func(b *testing.B, src []byte)
+	}{
+		{"EncodeToString", func(b *testing.B, src []byte) {
+			for i := 0; i < b.N; i++ {
+				benchutil.StringSink.Store(enc.EncodeToString(src))
+			}
+		}},
+		{"Encode", func(b *testing.B, src []byte) {
+			dst := make([]byte, enc.EncodedLen(len(src)))
+			for i := 0; i < b.N; i++ {
+				enc.Encode(dst, src)
+			}
+			benchutil.BytesSink.Store(dst)
+		}},
+		{"AppendEncode", func(b *testing.B, src []byte) {
+			buf := make([]byte, 0, enc.EncodedLen(len(src)))
+			for i := 0; i < b.N; i++ {
+				buf = enc.AppendEncode(buf[:0], src)
+			}
+			benchutil.BytesSink.Store(buf)
+		}},
+		{"NewEncoder", func(b *testing.B, src []byte) {
+			var out bytes.Buffer
+			out.Grow(enc.EncodedLen(len(src)))
+			for i := 0; i < b.N; i++ {
+				out.Reset()
+				w := base64.NewEncoder(enc, &out)
+				w.Write(src)
+				w.Close()
+			}
+			benchutil.BytesSink.Store(out.Bytes())
+		}},
+	}
+	for _, v := range variants {
+		b.Run(v.name, func(b *testing.B) {
+			benchutil.Sizes(b, codecSizes, func(b *testing.B, n int) {
+				src := benchutil.Bytes(n)
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				v.fn(b, src)
+			})
+		})
+	}
+}
+
+// errCorrupt is returned by the hand-rolled decoder for invalid input.
+var errCorrupt = errors.New("base64: illegal data")
+
+const stdAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// decodeTable maps a byte of the standard alphabet to its 6 bits and
+// every other byte to 0xff.
+var decodeTable = func() (t [256]byte) {
+	for i := range t {
+		t[i] = 0xff
+	}
+	for i := 0; i < len(stdAlphabet); i++ {
+		t[stdAlphabet[i]] = byte(i)
+	}
+	return t
+}()
+
+// appendTableDecode is a hand-rolled decoder of padded standard base64: a
+// 256 byte lookup table, four characters at a time, padding only in the
+// last quantum. It accepts exactly what base64.StdEncoding accepts, which
+// ignores non-zero bits after the last byte, except that it rejects the
+// line breaks the standard decoder skips.
+func appendTableDecode(dst, src []byte) ([]byte, error) {
+	if len(src)%4 != 0 {
+		return nil, errCorrupt
+	}
+	if len(src) == 0 {
+		return dst, nil
+	}
+	body, last := src[:len(src)-4], src[len(src)-4:]
+	for ; len(body) >= 4; body = body[4:] {
+		a, b, c, d := decodeTable[body[0]], decodeTable[body[1]], decodeTable[body[2]], decodeTable[body[3]]
+		if a|b|c|d == 0xff {
+			return nil, errCorrupt
+		}
+		v := uint32(a)<<18 | uint32(b)<<12 | uint32(c)<<6 | uint32(d)
+		dst = append(dst, byte(v>>16), byte(v>>8), byte(v))
+	}
+
+	a, b := decodeTable[last[0]], decodeTable[last[1]]
+	if a|b == 0xff {
+		return nil, errCorrupt
+	}
+	switch {
+	case last[2] == '=' && last[3] == '=':
+		return append(dst, a<<2|b>>4), nil
+	case last[3] == '=':
+		c := decodeTable[last[2]]
+		if c == 0xff {
+			return nil, errCorrupt
+		}
+		return append(dst, a<<2|b>>4, b<<4|c>>2), nil
+	default:
+		c, d := decodeTable[last[2]], decodeTable[last[3]]
+		if c|d == 0xff {
+			return nil, errCorrupt
+		}
+		return append(dst, a<<2|b>>4, b<<4|c>>2, c<<6|d), nil
+	}
+}
+
+// decodeFamily checks the decoding APIs and the hand-rolled decoder
+// against DecodeString. Decoders return nil for invalid input and a non
+// nil slice otherwise, also for an empty result.
+var decodeFamily = func() *benchutil.Family[[]byte, []byte] {
+	enc := base64.StdEncoding
+	// The reused buffers must not be nil, or an empty result would read
+	// as invalid input.
+	decodeBuf, appendBuf, tableBuf := []byte{}, []byte{}, []byte{}
+	return &benchutil.Family[[]byte, []byte]{
+		Reference: func(src []byte) []byte {
+			out, err := enc.DecodeString(string(src))
+			if err != nil {
+				return nil
+			}
+			return out
+		},
+		Variants: []benchutil.Variant[[]byte, []byte]{
+			{Name: "DecodeString", Fn: func(src []byte) []byte {
+				out, err := enc.DecodeString(string(src))
+				if err != nil {
+					return nil
+				}
+				return out
+			}},
+			{Name: "Decode", Fn: func(src []byte) []byte {
+				if n := enc.DecodedLen(len(src)); cap(decodeBuf) < n {
+					decodeBuf = make([]byte, n)
+				}
+				n, err := enc.Decode(decodeBuf[:cap(decodeBuf)], src)
+				if err != nil {
+					return nil
+				}
+				return decodeBuf[:n]
+			}},
+			{Name: "AppendDecode", Fn: func(src []byte) []byte {
+				out, err := enc.AppendDecode(appendBuf[:0], src)
+				if err != nil {
+					return nil
+				}
+				appendBuf = out
+				return out
+			}},
+			{Name: "NewDecoder", Fn: func(src []byte) []byte {
+				out, err := io.ReadAll(base64.NewDecoder(enc, bytes.NewReader(src)))
+				if err != nil {
+					return nil
+				}
+				return out
+			}},
+			{Name: "Table", Fn: func(src []byte) []byte {
+				out, err := appendTableDecode(tableBuf[:0], src)
+				if err != nil {
+					return nil
+				}
+				tableBuf = out
+				return out
+			}},
+		},
+		Inputs: func(g *benchutil.Gen) [][]byte {
+			in := [][]byte{
+				[]byte(""), []byte("QQ=="), []byte("QUI="), []byte("QUJD"),
+				[]byte("QR=="), []byte("QUJ="), // non-zero trailing bits, not Strict
+				[]byte("QUJ"), []byte("Q==="), []byte("=QUJ"), []byte("QU=D"),
+				[]byte("QUJD!UJD"), []byte("QUJD\x80UJD"),
+			}
+			for i := 0; i < 50; i++ {
+				in = append(in, enc.AppendEncode(nil, g.Bytes(g.Int(0, 300))))
+			}
+			return in
+		},
+		Equal: func(got, want []byte) bool {
+			return (got == nil) == (want == nil) && bytes.Equal(got, want)
+		},
+	}
+}()
+
+func TestBase64Decoders(t *testing.T) {
+	decodeFamily.Verify(t)
+}
+
+// BenchmarkBase64DecodeAPI compares the ways to call the decoder with the
+// hand-rolled lookup table decoder, all verified against DecodeString.
+func BenchmarkBase64DecodeAPI(b *testing.B) {
+	for _, v := range decodeFamily.Variants {
+		b.Run(v.Name, func(b *testing.B) {
+			benchutil.Sizes(b, codecSizes, func(b *testing.B, n int) {
+				src := base64.StdEncoding.AppendEncode(nil, benchutil.Bytes(n))
+				b.SetBytes(int64(n))
+				decodeFamily.BenchmarkVariant(b, v.Name, src)
+			})
+		})
+	}
+}
+
+// streamSizes are the inputs of the streaming benchmarks, large enough
+// that the encoder and decoder buffers are refilled many times.
+var streamSizes = []int{1 << 20, 16 << 20}
+
+// BenchmarkBase64Stream encodes and decodes large inputs through
+// NewEncoder and NewDecoder with io.Copy, as a proxy does for bodies.
+func BenchmarkBase64Stream(b *testing.B) {
+	enc := base64.StdEncoding
+	b.Run("Encoder", func(b *testing.B) {
+		benchutil.Sizes(b, streamSizes, func(b *testing.B, n int) {
+			src := benchutil.Bytes(n)
+			b.SetBytes(int64(n))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				w := base64.NewEncoder(enc, io.Discard)
+				if _, err := io.Copy(w, bytes.NewReader(src)); err != nil {
+					b.Fatal(err)
+				}
+				w.Close()
+			}
+		})
+	})
+	b.Run("Decoder", func(b *testing.B) {
+		benchutil.Sizes(b, streamSizes, func(b *testing.B, n int) {
+			src := enc.AppendEncode(nil, benchutil.Bytes(n))
+			b.SetBytes(int64(n))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := io.Copy(io.Discard, base64.NewDecoder(enc, bytes.NewReader(src))); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+}
+
+// codecs are the binary-to-text encodings next to base64, each appending
+// to a reused buffer.
+var codecs = []struct {
+	name   string
+	encode func(dst, src []byte) []byte
+	decode func(dst, src []byte) ([]byte, error)
+}{
+	{"Base64", base64.StdEncoding.AppendEncode, base64.StdEncoding.AppendDecode},
+	{"Base64Table", base64.StdEncoding.AppendEncode, appendTableDecode},
+	{"Base32", base32.StdEncoding.AppendEncode, base32.StdEncoding.AppendDecode},
+	{"Hex", hex.AppendEncode, hex.AppendDecode},
+	{"ASCII85", appendASCII85, decodeASCII85},
+}
+
+func appendASCII85(dst, src []byte) []byte {
+	n := len(dst)
+	dst = append(dst, make([]byte, ascii85.MaxEncodedLen(len(src)))...)
+	return dst[:n+ascii85.Encode(dst[n:], src)]
+}
+
+func decodeASCII85(dst, src []byte) ([]byte, error) {
+	n := len(dst)
+	// 5 characters decode to at most 4 bytes, but a short last group is
+	// written as a whole group of 4 before it is cut.
+	dst = append(dst, make([]byte, len(src)+4)...)
+	ndst, _, err := ascii85.Decode(dst[n:], src, true)
+	return dst[:n+ndst], err
+}
+
+// BenchmarkCodecEncode compares the encoders, base64 once more as the
+// baseline; Base64Table has no encoder of its own and is left out.
+func BenchmarkCodecEncode(b *testing.B) {
+	for _, c := range codecs {
+		if c.name == "Base64Table" {
+			continue
+		}
+		b.Run(c.name, func(b *testing.B) {
+			benchutil.Sizes(b, codecSizes, func(b *testing.B, n int) {
+				src := benchutil.Bytes(n)
+				buf := c.encode(nil, src)
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					buf = c.encode(buf[:0], src)
+				}
+				benchutil.BytesSink.Store(buf)
+			})
+		})
+	}
+}
+
+func BenchmarkCodecDecode(b *testing.B) {
+	for _, c := range codecs {
+		b.Run(c.name, func(b *testing.B) {
+			benchutil.Sizes(b, codecSizes, func(b *testing.B, n int) {
+				src := c.encode(nil, benchutil.Bytes(n))
+				buf := make([]byte, 0, len(src))
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					var err error
+					if buf, err = c.decode(buf[:0], src); err != nil {
+						b.Fatal(err)
+					}
+				}
+				benchutil.BytesSink.Store(buf)
+			})
+		})
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	g := benchutil.NewGen(benchutil.Seed)
+	for _, c := range codecs {
+		for _, n := range []int{0, 1, 2, 3, 4, 5, 63, 64, 65, 1000} {
+			src := g.Bytes(n)
+			out, err := c.decode(nil, c.encode(nil, src))
+			if err != nil || !bytes.Equal(out, src) {
+				t.Errorf("%s: round trip of %d bytes = %x, %v", c.name, n, out, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package base64
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// padding is how a validator treats the padding of the last quantum.
+type padding int
+
+const (
+	padRequired padding = iota // like StdEncoding: "=" up to a multiple of 4
+	padStrict                  // like StdEncoding.Strict(): also no stray bits
+	padOptional                // with or without "=", as JWTs and URLs drop it
+)
+
+// base64Pattern is the pattern of base64regex, compiled once.
+var base64Pattern = regexp.MustCompile(`^([A-Za-z0-9+/]{4})*([A-Za-z0-9+/]{4}|[A-Za-z0-9+/]{3}=|[A-Za-z0-9+/]{2}==)$`)
+
+// scanBase64 validates s with decodeTable in one pass and without
+// decoding. It rejects the line breaks the standard decoders skip.
+func scanBase64(s string, mode padding) bool {
+	n := len(s)
+	if mode != padOptional || n%4 == 0 {
+		if n%4 != 0 {
+			return false
+		}
+		if n >= 2 && s[n-1] == '=' {
+			n--
+			if s[n-1] == '=' {
+				n--
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		if decodeTable[s[i]] == 0xff {
+			return false
+		}
+	}
+	switch n % 4 {
+	case 1:
+		return false // 6 bits do not make a byte
+	case 2:
+		return mode != padStrict || decodeTable[s[n-1]]&0x0f == 0
+	case 3:
+		return mode != padStrict || decodeTable[s[n-1]]&0x03 == 0
+	}
+	return true
+}
+
+// decodePool holds the buffers of decodePooled, which grow to the
+// largest input seen.
+var decodePool = sync.Pool{New: func() any {
+	b := make([]byte, 0, 1<<10)
+	return &b
+}}
+
+// decodePooled validates s by decoding it into a pooled buffer. Inputs of
+// a length enc never produces are rejected by the DecodedLen arithmetic
+// before a buffer is taken, so unlike the decoders it does not skip line
+// breaks.
+func decodePooled(enc *base64.Encoding, s string) bool {
+	if enc.EncodedLen(enc.DecodedLen(len(s))) != len(s) {
+		return false
+	}
+	bp := decodePool.Get().(*[]byte)
+	if n := enc.DecodedLen(len(s)); cap(*bp) < n {
+		*bp = make([]byte, n)
+	}
+	_, err := enc.Decode((*bp)[:cap(*bp)], unsafe.Slice(unsafe.StringData(s), len(s)))
+	decodePool.Put(bp)
+	return err == nil
+}
+
+// lenientEncoding returns the encoding that decodes s with optional
+// padding.
+func lenientEncoding(s string) *base64.Encoding {
+	if len(s)%4 == 0 {
+		return base64.StdEncoding
+	}
+	return base64.RawStdEncoding
+}
+
+func decodeValid(enc *base64.Encoding) func(string) bool {
+	return func(s string) bool {
+		_, err := enc.DecodeString(s)
+		return err == nil
+	}
+}
+
+// noLineBreaks restricts the scanners and length checks to the inputs
+// they are specified for, the decoders skip '\r' and '\n'.
+func noLineBreaks(s string) bool { return !strings.ContainsAny(s, "\r\n") }
+
+// validateInputs are the edge cases of padding, alphabet and trailing
+// bits plus generated valid encodings.
+func validateInputs(g *benchutil.Gen) []string {
+	in := []string{
+		"", "=", "==", "====", "Q", "QQ", "QQ=", "QQ==", "QUI", "QUI=", "QUJD",
+		"QR==", "QUJ=", // stray bits, rejected in strict mode only
+		"Q===", "=QUJ", "QU=D", "QUJD=", "QUJD!UJD", "QUJD\x80UJD", "QUJD-_JD",
+		"QUJDQQ", "QUJDQQ=", "QUJDQUI", "QUJD\nQUJD", "Invalid string", "VmFsaWQgc3RyaW5nCg==",
+	}
+	for i := 0; i < 30; i++ {
+		s := base64.StdEncoding.EncodeToString(g.Bytes(g.Int(0, 100)))
+		in = append(in, s, strings.TrimRight(s, "="))
+	}
+	return in
+}
+
+// validateFamily compares validators of padded standard base64 with a
+// full decode.
+var validateFamily = &benchutil.Family[string, bool]{
+	Reference: decodeValid(base64.StdEncoding),
+	Variants: []benchutil.Variant[string, bool]{
+		{Name: "Decode", Fn: base64decode},
+		{Name: "Regex", Fn: base64regex, Accepts: func(s string) bool { return s != "" && noLineBreaks(s) }},
+		{Name: "RegexCompiled", Fn: base64Pattern.MatchString, Accepts: func(s string) bool { return s != "" && noLineBreaks(s) }},
+		{Name: "Table", Fn: func(s string) bool { return scanBase64(s, padRequired) }, Accepts: noLineBreaks},
+		{Name: "DecodedLenPool", Fn: func(s string) bool { return decodePooled(base64.StdEncoding, s) }, Accepts: noLineBreaks},
+	},
+	Inputs: validateInputs,
+}
+
+// paddingFamilies are the validators of strict and of lenient padding,
+// each checked against the standard decoder configured the same way.
+var paddingFamilies = []struct {
+	name   string
+	family *benchutil.Family[string, bool]
+}{
+	{"Strict", &benchutil.Family[string, bool]{
+		Reference: decodeValid(base64.StdEncoding.Strict()),
+		Variants: []benchutil.Variant[string, bool]{
+			{Name: "Decode", Fn: decodeValid(base64.StdEncoding.Strict())},
+			{Name: "Table", Fn: func(s string) bool { return scanBase64(s, padStrict) }, Accepts: noLineBreaks},
+			{Name: "DecodedLenPool", Fn: func(s string) bool { return decodePooled(base64.StdEncoding.Strict(), s) }, Accepts: noLineBreaks},
+		},
+		Inputs: validateInputs,
+	}},
+	{"Lenient", &benchutil.Family[string, bool]{
+		Reference: func(s string) bool { return decodeValid(lenientEncoding(s))(s) },
+		Variants: []benchutil.Variant[string, bool]{
+			{Name: "TrimDecode", Fn: func(s string) bool {
+				// Padding is at most "==" and only on a full quantum.
+				t := strings.TrimSuffix(strings.TrimSuffix(s, "="), "=")
+				if len(t) != len(s) && len(s)%4 != 0 {
+					return false
+				}
+				return decodeValid(base64.RawStdEncoding)(t)
+			}},
+			{Name: "Table", Fn: func(s string) bool { return scanBase64(s, padOptional) }, Accepts: noLineBreaks},
+			{Name: "DecodedLenPool", Fn: func(s string) bool { return decodePooled(lenientEncoding(s), s) }, Accepts: noLineBreaks},
+		},
+		Inputs: validateInputs,
+	}},
+}
+
+func TestBase64Validators(t *testing.T) {
+	validateFamily.Verify(t)
+	for _, p := range paddingFamilies {
+		t.Run(p.name, func(t *testing.T) { p.family.Verify(t) })
+	}
+}
+
+// validateSizes are the lengths of the encoded inputs.
+var validateSizes = []int{16, 256, 4 << 10, 64 << 10, 1 << 20}
+
+// validateKinds build inputs of n characters: a valid encoding with one
+// "=" of padding, and copies of it broken at the second character and in
+// the last full quantum before the padding. Which validators exit early
+// shows between the two invalid kinds.
+var validateKinds = []struct {
+	name  string
+	input func(n int) string
+}{
+	{"Valid", validInput},
+	{"InvalidEarly", func(n int) string { return breakAt(validInput(n), 1) }},
+	{"InvalidLate", func(n int) string { return breakAt(validInput(n), n-5) }},
+}
+
+func validInput(n int) string {
+	return base64.StdEncoding.EncodeToString(benchutil.Bytes(n/4*3 - 1))
+}
+
+func breakAt(s string, i int) string {
+	return s[:i] + "!" + s[i+1:]
+}
+
+// BenchmarkBase64Validate times the validators of padded base64 on every
+// kind of input.
+func BenchmarkBase64Validate(b *testing.B) {
+	for _, k := range validateKinds {
+		b.Run(k.name, func(b *testing.B) {
+			for _, v := range validateFamily.Variants {
+				b.Run(v.Name, func(b *testing.B) {
+					benchutil.Sizes(b, validateSizes, func(b *testing.B, n int) {
+						in := k.input(n)
+						b.SetBytes(int64(n))
+						validateFamily.BenchmarkVariant(b, v.Name, in)
+					})
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkBase64ValidatePadding times the validators of strict and
+// lenient padding, named by mode and variant.
+func BenchmarkBase64ValidatePadding(b *testing.B) {
+	for _, k := range validateKinds {
+		b.Run(k.name, func(b *testing.B) {
+			for _, p := range paddingFamilies {
+				for _, v := range p.family.Variants {
+					b.Run(p.name+v.Name, func(b *testing.B) {
+						benchutil.Sizes(b, validateSizes, func(b *testing.B, n int) {
+							in := k.input(n)
+							b.SetBytes(int64(n))
+							p.family.BenchmarkVariant(b, v.Name, in)
+						})
+					})
+				}
+			}
+		})
+	}
+}
@@ -5,76 +5,113 @@ package between
 
 import (
 	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 	"simonwaldherr.de/go/golibs/as"
 	"simonwaldherr.de/go/ranger"
-	"testing"
 )
 
-func BenchmarkNumberRegEx(b *testing.B) {
-	re := ranger.Compile(89, 1001)
-	re = "^(" + re + ")$"
-	b.ResetTimer()
-
-	for n := 0; n < b.N; n++ {
-		matched, err := regexp.MatchString(re, "404")
-		if !matched || err != nil {
-			b.Log("Error in Benchmark")
-		}
+const (
+	rangeMin = 89
+	rangeMax = 1001
+)
 
-		matched, err = regexp.MatchString(re, "2000")
-		if matched || err != nil {
-			b.Log("Error in Benchmark")
-		}
+var (
+	numberRegEx   = "^(" + ranger.Compile(rangeMin, rangeMax) + ")$"
+	fulltextRegEx = " (" + ranger.Compile(rangeMin, rangeMax) + ") "
+	digits        = regexp.MustCompile("[0-9]+")
+)
+
+// betweenPair is a value inside and one outside of the range,
+// both are checked in every iteration.
+type betweenPair [2]string
+
+func betweenEach(fn func(string) bool) func(betweenPair) [2]bool {
+	return func(p betweenPair) [2]bool {
+		return [2]bool{fn(p[0]), fn(p[1])}
 	}
 }
 
-func BenchmarkFulltextRegEx(b *testing.B) {
-	re := ranger.Compile(89, 1001)
-	re = " (" + re + ") "
-	b.ResetTimer()
-
-	for n := 0; n < b.N; n++ {
-		matched, err := regexp.MatchString(re, "lorem ipsum 404 dolor sit")
-		if !matched || err != nil {
-			b.Log("Error in Benchmark")
-		}
+func inRange(i int) bool {
+	return i >= rangeMin && i <= rangeMax
+}
+
+// sentence embeds a number in the text the fulltext variants search.
+func sentence(number string) string {
+	return "lorem ipsum " + number + " dolor sit"
+}
 
-		matched, err = regexp.MatchString(re, "lorem ipsum 2000 dolor sit")
-		if matched || err != nil {
-			b.Log("Error in Benchmark")
+func betweenInputs(text func(string) string) func(g *benchutil.Gen) []betweenPair {
+	return func(g *benchutil.Gen) []betweenPair {
+		pairs := []betweenPair{{text("404"), text("2000")}}
+		for _, edge := range []int{0, rangeMin - 1, rangeMin, rangeMax, rangeMax + 1} {
+			pairs = append(pairs, betweenPair{text(strconv.Itoa(edge)), text(strconv.Itoa(edge * 10))})
+		}
+		for range 200 {
+			pairs = append(pairs, betweenPair{text(strconv.Itoa(g.Int(0, 5000))), text(strconv.Itoa(g.Int(0, 5000)))})
 		}
+		return pairs
 	}
 }
 
-func BenchmarkNumberParse(b *testing.B) {
-	for n := 0; n < b.N; n++ {
-		i1 := as.Int("404")
-		i2 := as.Int("2000")
+var numberFamily = &benchutil.Family[betweenPair, [2]bool]{
+	Reference: betweenEach(func(s string) bool {
+		i, err := strconv.Atoi(s)
+		return err == nil && inRange(i)
+	}),
+	Variants: []benchutil.Variant[betweenPair, [2]bool]{
+		{Name: "NumberRegEx", Fn: betweenEach(func(s string) bool {
+			matched, err := regexp.MatchString(numberRegEx, s)
+			return matched && err == nil
+		})},
+		{Name: "NumberParse", Fn: betweenEach(func(s string) bool {
+			return inRange(int(as.Int(s)))
+		})},
+	},
+	Inputs: betweenInputs(func(s string) string { return s }),
+}
 
-		if i1 < 89 || i1 > 1001 {
-			b.Log("Error in Benchmark")
+var fulltextFamily = &benchutil.Family[betweenPair, [2]bool]{
+	Reference: betweenEach(func(s string) bool {
+		for _, field := range strings.Fields(s) {
+			if i, err := strconv.Atoi(field); err == nil {
+				return inRange(i)
+			}
 		}
+		return false
+	}),
+	Variants: []benchutil.Variant[betweenPair, [2]bool]{
+		{Name: "FulltextRegEx", Fn: betweenEach(func(s string) bool {
+			matched, err := regexp.MatchString(fulltextRegEx, s)
+			return matched && err == nil
+		})},
+		{Name: "FulltextParse", Fn: betweenEach(func(s string) bool {
+			return inRange(int(as.Int(digits.FindString(s))))
+		})},
+	},
+	Inputs: betweenInputs(sentence),
+}
 
-		if !(i2 < 89 || i2 > 1001) {
-			b.Log("Error in Benchmark")
-		}
-	}
+func TestBetween(t *testing.T) {
+	numberFamily.Verify(t)
+	fulltextFamily.Verify(t)
 }
 
-func BenchmarkFulltextParse(b *testing.B) {
-	re := regexp.MustCompile("[0-9]+")
-	b.ResetTimer()
+func BenchmarkNumberRegEx(b *testing.B) {
+	numberFamily.BenchmarkVariant(b, "NumberRegEx", betweenPair{"404", "2000"})
+}
 
-	for n := 0; n < b.N; n++ {
-		i1 := as.Int(re.FindString("lorem ipsum 404 dolor sit"))
-		i2 := as.Int(re.FindString("lorem ipsum 2000 dolor sit"))
+func BenchmarkFulltextRegEx(b *testing.B) {
+	fulltextFamily.BenchmarkVariant(b, "FulltextRegEx", betweenPair{sentence("404"), sentence("2000")})
+}
 
-		if i1 < 89 || i1 > 1001 {
-			b.Log("Error in Benchmark")
-		}
+func BenchmarkNumberParse(b *testing.B) {
+	numberFamily.BenchmarkVariant(b, "NumberParse", betweenPair{"404", "2000"})
+}
 
-		if !(i2 < 89 || i2 > 1001) {
-			b.Log("Error in Benchmark")
-		}
-	}
+func BenchmarkFulltextParse(b *testing.B) {
+	fulltextFamily.BenchmarkVariant(b, "FulltextParse", betweenPair{sentence("404"), sentence("2000")})
 }
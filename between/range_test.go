@@ -0,0 +1,289 @@
+package between
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+	"simonwaldherr.de/go/golibs/as"
+	"simonwaldherr.de/go/ranger"
+)
+
+// checkRange is a range [lo, hi] with the state the variants precompute
+// for it: the ranger regex, a bitset and, for narrow ranges, a table of
+// the decimal strings inside.
+type checkRange struct {
+	lo, hi int
+	regex  *regexp.Regexp
+	bits   []uint64
+	table  map[string]struct{}
+
+	once sync.Once
+	err  error // of checkRegex
+}
+
+// tableLimit is the widest range that gets a lookup table, one string per
+// value does not fit memory much beyond.
+const tableLimit = 1 << 16
+
+func newCheckRange(lo, hi int) *checkRange {
+	r := &checkRange{
+		lo:    lo,
+		hi:    hi,
+		regex: regexp.MustCompile("^(" + ranger.Compile(lo, hi) + ")$"),
+		bits:  make([]uint64, hi/64+1),
+	}
+	for i := lo; i <= hi; i++ {
+		r.bits[i/64] |= 1 << (i % 64)
+	}
+	if hi-lo < tableLimit {
+		r.table = make(map[string]struct{}, hi-lo+1)
+		for i := lo; i <= hi; i++ {
+			r.table[strconv.Itoa(i)] = struct{}{}
+		}
+	}
+	return r
+}
+
+func (r *checkRange) String() string {
+	return fmt.Sprintf("[%d, %d]", r.lo, r.hi)
+}
+
+// checkRegex compares the ranger regex with the numeric comparison for
+// every value of the range and up to as many on either side of it, so a
+// regex that is off at a digit boundary inside the range cannot be timed.
+func (r *checkRange) checkRegex() error {
+	r.once.Do(func() {
+		span := min(r.hi-r.lo+1, 10_000)
+		for i := max(r.lo-span, 0); i <= r.hi+span; i++ {
+			if got, want := r.regex.MatchString(strconv.Itoa(i)), i >= r.lo && i <= r.hi; got != want {
+				r.err = fmt.Errorf("regex of %v matches %d: %t, want %t", r, i, got, want)
+				return
+			}
+		}
+	})
+	return r.err
+}
+
+// rangeMagnitudes are the upper bounds of the random ranges, the number
+// of digits decides the length of the ranger regex.
+var rangeMagnitudes = []int{100, 10_000, 1_000_000}
+
+var (
+	rangesMu sync.Mutex
+	ranges   = make(map[int][]*checkRange)
+)
+
+// randomRanges is the number of random ranges per magnitude, next to the
+// edge shapes of rangesOf.
+const randomRanges = 24
+
+// rangesOf returns ranges below magnitude, the same ones in every run:
+// the edge shapes, a single value, ranges from 0 and up to magnitude-1 and
+// ranges across every digit-count boundary such as 95-105 or 999-1001,
+// followed by random ranges.
+func rangesOf(magnitude int) []*checkRange {
+	rangesMu.Lock()
+	defer rangesMu.Unlock()
+	if rs, ok := ranges[magnitude]; ok {
+		return rs
+	}
+	g := benchutil.NewGen(benchutil.Seed + uint64(magnitude))
+	top := magnitude - 1
+	bounds := [][2]int{
+		{0, 0}, {top, top}, {0, top},
+		{0, g.Int(0, magnitude)}, {g.Int(0, magnitude), top},
+	}
+	v := g.Int(0, magnitude)
+	bounds = append(bounds, [2]int{v, v})
+	for p := 10; p < magnitude; p *= 10 {
+		bounds = append(bounds, [2]int{p - 1, p}, [2]int{p - 1, p + 1}, [2]int{p - 5, p + 5})
+	}
+	for range randomRanges {
+		lo := g.Int(0, magnitude)
+		bounds = append(bounds, [2]int{lo, g.Int(lo, magnitude)})
+	}
+	rs := make([]*checkRange, len(bounds))
+	for i, b := range bounds {
+		rs[i] = newCheckRange(b[0], b[1])
+	}
+	ranges[magnitude] = rs
+	return rs
+}
+
+// rangeQuery asks whether value, a decimal without sign or leading zeros,
+// lies in r.
+type rangeQuery struct {
+	r     *checkRange
+	value string
+}
+
+// rangeQueries returns n queries of random values below twice magnitude
+// against the ranges of magnitude, so about half of them are out of range.
+func rangeQueries(g *benchutil.Gen, magnitude, n int) []rangeQuery {
+	rs := rangesOf(magnitude)
+	qs := make([]rangeQuery, n)
+	for i := range qs {
+		r := rs[g.Int(0, len(rs))]
+		qs[i] = rangeQuery{r, strconv.Itoa(g.Int(0, 2*magnitude))}
+	}
+	return qs
+}
+
+// queryEach applies fn to every query into a buffer of its own, which is
+// reused between calls.
+func queryEach(fn func(r *checkRange, s string) bool) func([]rangeQuery) []bool {
+	buf := []bool{}
+	return func(qs []rangeQuery) []bool {
+		buf = buf[:0]
+		for _, q := range qs {
+			buf = append(buf, fn(q.r, q.value))
+		}
+		return buf
+	}
+}
+
+// scanInRange parses s digit by digit and gives up as soon as the value
+// exceeds hi, long numbers are rejected after the digits of hi.
+func scanInRange(s string, lo, hi int) bool {
+	if s == "" {
+		return false
+	}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		d := s[i] - '0'
+		if d > 9 {
+			return false
+		}
+		if n = n*10 + int(d); n > hi {
+			return false
+		}
+	}
+	return n >= lo
+}
+
+// inBitset parses s like scanInRange and looks the value up in the bits
+// of the range instead of comparing it.
+func inBitset(bits []uint64, s string) bool {
+	if s == "" {
+		return false
+	}
+	limit := len(bits) * 64
+	n := 0
+	for i := 0; i < len(s); i++ {
+		d := s[i] - '0'
+		if d > 9 {
+			return false
+		}
+		if n = n*10 + int(d); n >= limit {
+			return false
+		}
+	}
+	return bits[n/64]&(1<<(n%64)) != 0
+}
+
+var rangeFamily = &benchutil.Family[[]rangeQuery, []bool]{
+	Reference: func(qs []rangeQuery) []bool {
+		out := make([]bool, len(qs))
+		for i, q := range qs {
+			n, err := strconv.Atoi(q.value)
+			out[i] = err == nil && n >= q.r.lo && n <= q.r.hi
+		}
+		return out
+	},
+	Variants: []benchutil.Variant[[]rangeQuery, []bool]{
+		{Name: "RegEx", Fn: queryEach(func(r *checkRange, s string) bool {
+			return r.regex.MatchString(s)
+		})},
+		{Name: "Atoi", Fn: queryEach(func(r *checkRange, s string) bool {
+			n, err := strconv.Atoi(s)
+			return err == nil && n >= r.lo && n <= r.hi
+		})},
+		{Name: "AsInt", Fn: queryEach(func(r *checkRange, s string) bool {
+			n := int(as.Int(s))
+			return n >= r.lo && n <= r.hi
+		})},
+		{Name: "Scanner", Fn: queryEach(func(r *checkRange, s string) bool {
+			return scanInRange(s, r.lo, r.hi)
+		})},
+		{Name: "Bitset", Fn: queryEach(func(r *checkRange, s string) bool {
+			return inBitset(r.bits, s)
+		})},
+		{Name: "Table", Fn: queryEach(func(r *checkRange, s string) bool {
+			_, ok := r.table[s]
+			return ok
+		}), Accepts: func(qs []rangeQuery) bool {
+			for _, q := range qs {
+				if q.r.table == nil {
+					return false
+				}
+			}
+			return true
+		}},
+	},
+	Inputs: func(g *benchutil.Gen) [][]rangeQuery {
+		var in [][]rangeQuery
+		for _, m := range rangeMagnitudes {
+			var edges []rangeQuery
+			for _, r := range rangesOf(m) {
+				for _, v := range []int{0, r.lo - 1, r.lo, r.hi, r.hi + 1, 10 * r.hi} {
+					if v >= 0 {
+						edges = append(edges, rangeQuery{r, strconv.Itoa(v)})
+					}
+				}
+			}
+			in = append(in, edges, rangeQueries(g, m, 500))
+		}
+		return in
+	},
+}
+
+// TestRangerRegex checks the regex of every random range against the
+// numeric comparison over the whole range and its neighbourhood.
+func TestRangerRegex(t *testing.T) {
+	for _, m := range rangeMagnitudes {
+		if m > 10_000 && testing.Short() {
+			continue
+		}
+		for _, r := range rangesOf(m) {
+			if err := r.checkRegex(); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+}
+
+func TestRangeCheck(t *testing.T) {
+	rangeFamily.Verify(t)
+}
+
+// rangeBatch is the number of queries per op.
+const rangeBatch = 1000
+
+// BenchmarkRangeCheck answers a batch of queries of random values against
+// random ranges below every magnitude. The regexes of the ranges are
+// checked exhaustively first, ns/value is the time of a single check.
+func BenchmarkRangeCheck(b *testing.B) {
+	for _, v := range rangeFamily.Variants {
+		b.Run(v.Name, func(b *testing.B) {
+			for _, m := range rangeMagnitudes {
+				b.Run(fmt.Sprintf("max=%d", m), func(b *testing.B) {
+					for _, r := range rangesOf(m) {
+						if err := r.checkRegex(); err != nil {
+							b.Fatal(err)
+						}
+					}
+					qs := rangeQueries(benchutil.NewGen(benchutil.Seed), m, rangeBatch)
+					if v.Accepts != nil && !v.Accepts(qs) {
+						b.Skip("no lookup table for ranges this wide")
+					}
+					rangeFamily.BenchmarkVariant(b, v.Name, qs)
+					b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*rangeBatch), "ns/value")
+				})
+			}
+		})
+	}
+}
@@ -1,35 +1,43 @@
+// Package caseinsensitivecompare benchmarks case-insensitive string
+// comparison. strings.EqualFold is fast and right for ASCII and simple
+// folds such as "Müller" and Greek sigma, but only full case folding with
+// golang.org/x/text/cases matches "straße" with "STRASSE" or handles
+// ligatures and the dotted İ. Comparing ToLower or ToUpper results is
+// slower than EqualFold and wrong on more inputs. TestFoldDeclarations
+// logs which input classes every variant compares correctly. The FoldSet
+// benchmarks look names up case-insensitively in sets of 10 to 100k keys.
 package caseinsensitivecompare
 
 import (
 	"strings"
 	"testing"
-)
 
-var caseInsensitiveCompareResult bool
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
 
 func BenchmarkEqualFold(b *testing.B) {
 	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		caseInsensitiveCompareResult = strings.EqualFold("abc", "ABC")
-		caseInsensitiveCompareResult = strings.EqualFold("ABC", "ABC")
-		caseInsensitiveCompareResult = strings.EqualFold("1aBcD", "1AbCd")
+		benchutil.BoolSink.Store(strings.EqualFold("abc", "ABC"))
+		benchutil.BoolSink.Store(strings.EqualFold("ABC", "ABC"))
+		benchutil.BoolSink.Store(strings.EqualFold("1aBcD", "1AbCd"))
 	}
 }
 
 func BenchmarkToUpper(b *testing.B) {
 	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		caseInsensitiveCompareResult = strings.ToUpper("abc") == strings.ToUpper("ABC")
-		caseInsensitiveCompareResult = strings.ToUpper("ABC") == strings.ToUpper("ABC")
-		caseInsensitiveCompareResult = strings.ToUpper("1aBcD") == strings.ToUpper("1AbCd")
+		benchutil.BoolSink.Store(strings.ToUpper("abc") == strings.ToUpper("ABC"))
+		benchutil.BoolSink.Store(strings.ToUpper("ABC") == strings.ToUpper("ABC"))
+		benchutil.BoolSink.Store(strings.ToUpper("1aBcD") == strings.ToUpper("1AbCd"))
 	}
 }
 
 func BenchmarkToLower(b *testing.B) {
 	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		caseInsensitiveCompareResult = strings.ToLower("abc") == strings.ToLower("ABC")
-		caseInsensitiveCompareResult = strings.ToLower("ABC") == strings.ToLower("ABC")
-		caseInsensitiveCompareResult = strings.ToLower("1aBcD") == strings.ToLower("1AbCd")
+		benchutil.BoolSink.Store(strings.ToLower("abc") == strings.ToLower("ABC"))
+		benchutil.BoolSink.Store(strings.ToLower("ABC") == strings.ToLower("ABC"))
+		benchutil.BoolSink.Store(strings.ToLower("1aBcD") == strings.ToLower("1AbCd"))
 	}
 }
@@ -0,0 +1,263 @@
+package caseinsensitivecompare
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+	"golang.org/x/text/cases"
+)
+
+// The classes of input a case-insensitive comparison meets in user names.
+// The reference is full Unicode case folding, locale independent, as
+// cases.Fold implements it: "straße" equals "STRASSE", and "İ" equals
+// "i̇" (i and a combining dot) but neither "i" nor "I".
+const (
+	classASCII   = "ASCII"   // letters, digits and punctuation
+	classSimple  = "Simple"  // one rune folds to one rune: "Müller", Kelvin sign
+	classSigma   = "Sigma"   // final ς next to σ and Σ
+	classSharpS  = "SharpS"  // ß folds to "ss"
+	classTurkish = "Turkish" // dotted İ and dotless ı
+	classFull    = "Full"    // other one-to-many folds: ligatures, "ǰ"
+)
+
+// foldPair is a comparison of a and b, also as byte slices for
+// bytes.EqualFold.
+type foldPair struct {
+	class  string
+	a, b   string
+	ab, bb []byte
+}
+
+func pair(class, a, b string) foldPair {
+	return foldPair{class, a, b, []byte(a), []byte(b)}
+}
+
+// foldPairs are the hand-written inputs, equal and unequal ones of every
+// class.
+var foldPairs = []foldPair{
+	pair(classASCII, "abc", "ABC"),
+	pair(classASCII, "1aBcD", "1AbCd"),
+	pair(classASCII, "abc", "abd"),
+	pair(classASCII, "abc", "abcd"),
+	pair(classASCII, "", ""),
+	pair(classASCII, "John.Doe@Example.com", "john.doe@example.COM"),
+	pair(classSimple, "Müller", "MÜLLER"),
+	pair(classSimple, "Müller", "Muller"),
+	pair(classSimple, "École", "éCOLE"),
+	pair(classSimple, "K", "k"), // Kelvin sign
+	pair(classSimple, "ǅ", "ǆ"),
+	pair(classSimple, "ß", "ẞ"),
+	pair(classSigma, "σ", "ς"),
+	pair(classSigma, "ΣΊΣΥΦΟΣ", "σίσυφος"),
+	pair(classSigma, "ΟΔΥΣΣΕΥΣ", "οδυσσευς"),
+	pair(classSharpS, "straße", "STRASSE"),
+	pair(classSharpS, "ß", "ss"),
+	pair(classSharpS, "Weiß", "weiss"),
+	pair(classSharpS, "Weiß", "weis"),
+	pair(classTurkish, "İstanbul", "istanbul"),
+	pair(classTurkish, "ISTANBUL", "ıstanbul"),
+	pair(classTurkish, "İ", "i̇"),
+	pair(classTurkish, "Iğdır", "IĞDIR"),
+	pair(classFull, "ﬁle", "FILE"),
+	pair(classFull, "ǰ", "J̌"),
+	pair(classFull, "ﬀ", "ff"),
+}
+
+// foldVariant is a comparison with the classes it is correct on, checked
+// by TestFoldDeclarations in both directions.
+type foldVariant struct {
+	name    string
+	fn      func(p foldPair) bool
+	correct []string
+}
+
+var fold = cases.Fold()
+
+var foldVariants = []foldVariant{
+	{"EqualFold", func(p foldPair) bool { return strings.EqualFold(p.a, p.b) },
+		[]string{classASCII, classSimple, classSigma}},
+	{"BytesEqualFold", func(p foldPair) bool { return bytes.EqualFold(p.ab, p.bb) },
+		[]string{classASCII, classSimple, classSigma}},
+	{"ASCIIFastPath", func(p foldPair) bool { return equalFoldASCII(p.a, p.b) },
+		[]string{classASCII, classSimple, classSigma}},
+	{"ToLower", func(p foldPair) bool { return strings.ToLower(p.a) == strings.ToLower(p.b) },
+		[]string{classASCII, classSimple}},
+	// The Kelvin sign and ẞ are upper case letters that ToUpper leaves as
+	// they are, while k and ß upper-case to K and ß, so they never match.
+	{"ToUpper", func(p foldPair) bool { return strings.ToUpper(p.a) == strings.ToUpper(p.b) },
+		[]string{classASCII, classSigma}},
+	{"CasesFold", func(p foldPair) bool { return fold.String(p.a) == fold.String(p.b) },
+		[]string{classASCII, classSimple, classSigma, classSharpS, classTurkish, classFull}},
+}
+
+// equalFoldASCII compares ASCII bytes with a bit trick and hands the rest
+// to strings.EqualFold at the first byte that is not ASCII, so it is as
+// correct as EqualFold and faster only where the input is ASCII.
+func equalFoldASCII(a, b string) bool {
+	if len(a) != len(b) {
+		// Only ASCII has a fold of the same length for sure.
+		if !isASCII(a) || !isASCII(b) {
+			return strings.EqualFold(a, b)
+		}
+		return false
+	}
+	for i := 0; i < len(a); i++ {
+		x, y := a[i], b[i]
+		if x|y >= utf8.RuneSelf {
+			return strings.EqualFold(a[i:], b[i:])
+		}
+		if x == y {
+			continue
+		}
+		if x|0x20 != y|0x20 || x|0x20 < 'a' || x|0x20 > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// foldFamily checks every variant against full case folding on the
+// classes it declares.
+var foldFamily = func() *benchutil.Family[foldPair, bool] {
+	f := &benchutil.Family[foldPair, bool]{
+		Reference: func(p foldPair) bool {
+			return fold.String(p.a) == fold.String(p.b)
+		},
+		Inputs: func(g *benchutil.Gen) []foldPair {
+			in := slices.Clone(foldPairs)
+			for range 100 {
+				a := g.ASCII(g.Int(0, 40))
+				b := swapCase(a)
+				if g.Int(0, 2) == 0 && len(b) > 0 {
+					i := g.Int(0, len(b))
+					b = b[:i] + string(rune('!'+g.Int(0, 94))) + b[i+1:]
+				}
+				in = append(in, pair(classASCII, a, b))
+			}
+			return in
+		},
+	}
+	for _, v := range foldVariants {
+		f.Variants = append(f.Variants, benchutil.Variant[foldPair, bool]{
+			Name:    v.name,
+			Fn:      v.fn,
+			Accepts: func(p foldPair) bool { return slices.Contains(v.correct, p.class) },
+		})
+	}
+	return f
+}()
+
+// swapCase swaps the case of the ASCII letters of s.
+func swapCase(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c|0x20 >= 'a' && c|0x20 <= 'z' {
+			b[i] = c ^ 0x20
+		}
+	}
+	return string(b)
+}
+
+func TestFold(t *testing.T) {
+	foldFamily.Verify(t)
+}
+
+// TestFoldDeclarations checks that no variant declares too little: on
+// every class it leaves out, it gets at least one pair wrong. It logs the
+// matrix of the classes, which is the correctness table of the package.
+func TestFoldDeclarations(t *testing.T) {
+	classes := []string{classASCII, classSimple, classSigma, classSharpS, classTurkish, classFull}
+	for _, v := range foldVariants {
+		row := v.name + ":"
+		for _, class := range classes {
+			if slices.Contains(v.correct, class) {
+				row += " " + class
+				continue
+			}
+			wrong := false
+			for _, p := range foldPairs {
+				if p.class == class && v.fn(p) != foldFamily.Reference(p) {
+					wrong = true
+				}
+			}
+			if !wrong {
+				t.Errorf("%s is correct on all %s pairs but does not declare it", v.name, class)
+			}
+		}
+		t.Log(row)
+	}
+}
+
+// BenchmarkFold compares a pair of every class with each variant that is
+// correct on it.
+func BenchmarkFold(b *testing.B) {
+	for _, p := range []foldPair{
+		pair(classASCII, "1aBcD", "1AbCd"),
+		pair(classSimple, "Müller", "MÜLLER"),
+		pair(classSigma, "ΟΔΥΣΣΕΥΣ", "οδυσσευς"),
+		pair(classSharpS, "Straße", "STRASSE"),
+		pair(classTurkish, "İstanbul", "i̇stanbul"),
+		pair(classFull, "ﬁle", "FILE"),
+	} {
+		b.Run(p.class, func(b *testing.B) {
+			benchmarkFold(b, p)
+		})
+	}
+}
+
+func benchmarkFold(b *testing.B, p foldPair) {
+	for _, v := range foldFamily.Variants {
+		b.Run(v.Name, func(b *testing.B) {
+			if !v.Accepts(p) {
+				b.Skipf("%s is not correct on %s input", v.Name, p.class)
+			}
+			foldFamily.BenchmarkVariant(b, v.Name, p)
+		})
+	}
+}
+
+// foldSizes are the lengths of the long inputs, up to a document title.
+var foldSizes = []int{16, 256, 4 << 10}
+
+// BenchmarkFoldLong compares long ASCII strings of swapped case that are
+// equal or differ in the second or the second to last byte. Mismatches
+// early are where the variants that convert the whole string lose.
+func BenchmarkFoldLong(b *testing.B) {
+	for _, kind := range []struct {
+		name string
+		at   func(n int) int // index of the mismatch, -1 for none
+	}{
+		{"Equal", func(int) int { return -1 }},
+		{"EarlyMismatch", func(int) int { return 1 }},
+		{"LateMismatch", func(n int) int { return n - 2 }},
+	} {
+		b.Run(kind.name, func(b *testing.B) {
+			for _, v := range foldFamily.Variants {
+				b.Run(v.Name, func(b *testing.B) {
+					benchutil.Sizes(b, foldSizes, func(b *testing.B, n int) {
+						a := benchutil.NewGen(benchutil.Seed).ASCII(n)
+						c := []byte(swapCase(a))
+						if i := kind.at(n); i >= 0 {
+							c[i] ^= 0x01 // another letter, whatever the case
+						}
+						b.SetBytes(int64(n))
+						foldFamily.BenchmarkVariant(b, v.Name, pair(classASCII, a, string(c)))
+					})
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package caseinsensitivecompare
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// The lookup structures find a key in a set of keys the way
+// strings.EqualFold compares them, simple case folding: correct on the
+// ASCII, Simple and Sigma classes of fold_test.go. The ToLower map keeps
+// the flaw of ToLower and is only correct on ASCII and Simple.
+
+// foldRune returns the smallest rune of the case folding orbit of r, the
+// same rune for all runes strings.EqualFold treats as equal.
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'a' <= r && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		return r
+	}
+	m := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		m = min(m, f)
+	}
+	return m
+}
+
+// appendFoldKey appends the fold key of s to dst, the folded runes of s.
+// Two strings have the same fold key if and only if EqualFold holds.
+func appendFoldKey(dst []byte, s string) []byte {
+	for _, r := range s {
+		dst = utf8.AppendRune(dst, foldRune(r))
+	}
+	return dst
+}
+
+// compareFold orders strings by their fold keys without building them. It
+// is 0 exactly when strings.EqualFold(a, b).
+func compareFold(a, b string) int {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if c := foldRune(ra) - foldRune(rb); c != 0 {
+			return int(c)
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return len(a) - len(b)
+}
+
+// foldSet is a set of keys with a case-insensitive Contains.
+type foldSet interface {
+	Contains(s string) bool
+}
+
+type lowerMap map[string]struct{}
+
+func newLowerMap(keys []string) foldSet {
+	m := make(lowerMap, len(keys))
+	for _, k := range keys {
+		m[strings.ToLower(k)] = struct{}{}
+	}
+	return m
+}
+
+func (m lowerMap) Contains(s string) bool {
+	_, ok := m[strings.ToLower(s)]
+	return ok
+}
+
+// foldKeyMap is keyed by the fold keys of the keys. Contains builds the
+// fold key of s in a buffer it reuses, so it is not safe for concurrent
+// use.
+type foldKeyMap struct {
+	m   map[string]struct{}
+	buf []byte
+}
+
+func newFoldKeyMap(keys []string) foldSet {
+	f := &foldKeyMap{m: make(map[string]struct{}, len(keys))}
+	for _, k := range keys {
+		f.m[string(appendFoldKey(nil, k))] = struct{}{}
+	}
+	return f
+}
+
+func (f *foldKeyMap) Contains(s string) bool {
+	f.buf = appendFoldKey(f.buf[:0], s)
+	_, ok := f.m[string(f.buf)]
+	return ok
+}
+
+// sortedFold is sorted by compareFold and searched in binary.
+type sortedFold []string
+
+func newSortedFold(keys []string) foldSet {
+	s := slices.Clone(keys)
+	slices.SortFunc(s, compareFold)
+	return sortedFold(s)
+}
+
+func (s sortedFold) Contains(key string) bool {
+	_, ok := slices.BinarySearchFunc(s, key, compareFold)
+	return ok
+}
+
+// foldTrie has an edge per folded rune.
+type foldTrie struct {
+	children map[rune]*foldTrie
+	end      bool
+}
+
+func newFoldTrie(keys []string) foldSet {
+	root := &foldTrie{}
+	for _, k := range keys {
+		n := root
+		for _, r := range k {
+			r = foldRune(r)
+			child, ok := n.children[r]
+			if !ok {
+				if n.children == nil {
+					n.children = make(map[rune]*foldTrie)
+				}
+				child = &foldTrie{}
+				n.children[r] = child
+			}
+			n = child
+		}
+		n.end = true
+	}
+	return root
+}
+
+func (t *foldTrie) Contains(s string) bool {
+	n := t
+	for _, r := range s {
+		if n = n.children[foldRune(r)]; n == nil {
+			return false
+		}
+	}
+	return n.end
+}
+
+// foldSets are the lookup structures by name.
+var foldSets = []struct {
+	name  string
+	build func(keys []string) foldSet
+}{
+	{"ToLowerMap", newLowerMap},
+	{"FoldKeyMap", newFoldKeyMap},
+	{"SortedSlice", newSortedFold},
+	{"Trie", newFoldTrie},
+}
+
+// lookupBatch is a set of keys in every structure and the queries to
+// look up in them.
+type lookupBatch struct {
+	keys    []string
+	sets    []foldSet // by index of foldSets
+	queries []string
+}
+
+func newLookupBatch(keys, queries []string) *lookupBatch {
+	l := &lookupBatch{keys: keys, queries: queries}
+	for _, s := range foldSets {
+		l.sets = append(l.sets, s.build(keys))
+	}
+	return l
+}
+
+// userNames returns n user names that differ under case folding, mostly
+// ASCII with some Latin-1 and Greek letters.
+func userNames(g *benchutil.Gen, n int) []string {
+	const extra = "äöüßéñøçΔλπ"
+	seen := make(map[string]bool, n)
+	keys := make([]string, 0, n)
+	for len(keys) < n {
+		var sb strings.Builder
+		for range g.Int(4, 16) {
+			if g.Int(0, 20) == 0 {
+				r := []rune(extra)
+				sb.WriteRune(r[g.Int(0, len(r))])
+			} else {
+				sb.WriteByte(byte('a' + g.Int(0, 26)))
+			}
+		}
+		k := sb.String()
+		if fk := string(appendFoldKey(nil, k)); !seen[fk] {
+			seen[fk] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// lookupQueries returns n queries, half of them keys in another case and
+// half of them names that are no key.
+func lookupQueries(g *benchutil.Gen, keys []string, n int) []string {
+	others := userNames(g, n)
+	qs := make([]string, n)
+	for i := range qs {
+		if i%2 == 0 {
+			qs[i] = strings.ToUpper(keys[g.Int(0, len(keys))])
+		} else {
+			qs[i] = swapCase(others[i])
+		}
+	}
+	return qs
+}
+
+// hasSigma reports whether s has a Greek sigma, which ToLower does not
+// fold.
+func hasSigma(s string) bool {
+	return strings.ContainsAny(s, "Σσς")
+}
+
+func lookupEach(set int) func(*lookupBatch) []bool {
+	buf := []bool{}
+	return func(l *lookupBatch) []bool {
+		buf = buf[:0]
+		for _, q := range l.queries {
+			buf = append(buf, l.sets[set].Contains(q))
+		}
+		return buf
+	}
+}
+
+// lookupFamily checks the structures against a linear scan with
+// strings.EqualFold.
+var lookupFamily = func() *benchutil.Family[*lookupBatch, []bool] {
+	f := &benchutil.Family[*lookupBatch, []bool]{
+		Reference: func(l *lookupBatch) []bool {
+			out := make([]bool, len(l.queries))
+			for i, q := range l.queries {
+				out[i] = slices.ContainsFunc(l.keys, func(k string) bool { return strings.EqualFold(k, q) })
+			}
+			return out
+		},
+		Inputs: func(g *benchutil.Gen) []*lookupBatch {
+			var in []*lookupBatch
+			for _, n := range []int{1, 10, 1000} {
+				keys := userNames(g, n)
+				in = append(in, newLookupBatch(keys, lookupQueries(g, keys, 200)))
+			}
+			pairs := make([]string, 0, len(foldPairs))
+			queries := make([]string, 0, len(foldPairs))
+			for _, p := range foldPairs {
+				if p.class == classASCII || p.class == classSimple || p.class == classSigma {
+					pairs = append(pairs, p.a)
+					queries = append(queries, p.b)
+				}
+			}
+			keys := slices.Compact(pairs) // pairs with the same a are next to each other
+			return append(in, newLookupBatch(keys, append(queries, "", "x", "MÜLLERX")))
+		},
+	}
+	for i, s := range foldSets {
+		v := benchutil.Variant[*lookupBatch, []bool]{Name: s.name, Fn: lookupEach(i)}
+		if s.name == "ToLowerMap" {
+			v.Accepts = func(l *lookupBatch) bool {
+				return !slices.ContainsFunc(l.keys, hasSigma) && !slices.ContainsFunc(l.queries, hasSigma)
+			}
+		}
+		f.Variants = append(f.Variants, v)
+	}
+	return f
+}()
+
+func TestLookup(t *testing.T) {
+	lookupFamily.Verify(t)
+}
+
+func TestCompareFold(t *testing.T) {
+	for _, p := range foldPairs {
+		if got, want := compareFold(p.a, p.b) == 0, strings.EqualFold(p.a, p.b); got != want {
+			t.Errorf("compareFold(%q, %q) == 0 is %t, EqualFold is %t", p.a, p.b, got, want)
+		}
+	}
+}
+
+// lookupSizes are the numbers of keys, from a handful of reserved names
+// to a user directory.
+var lookupSizes = []int{10, 100, 1000, 10_000, 100_000}
+
+// lookupBatchSize is the number of queries per op of
+// BenchmarkFoldSetLookup.
+const lookupBatchSize = 1000
+
+var lookupBatches = make(map[int]*lookupBatch)
+
+// lookupBatchOf returns the batch of n keys, built once.
+func lookupBatchOf(n int) *lookupBatch {
+	if l, ok := lookupBatches[n]; ok {
+		return l
+	}
+	g := benchutil.NewGen(benchutil.Seed)
+	keys := userNames(g, n)
+	l := newLookupBatch(keys, lookupQueries(g, keys, lookupBatchSize))
+	lookupBatches[n] = l
+	return l
+}
+
+// BenchmarkFoldSetLookup looks up a batch of queries, half hits in
+// another case and half misses. ns/lookup is the time of one query.
+func BenchmarkFoldSetLookup(b *testing.B) {
+	for _, v := range lookupFamily.Variants {
+		b.Run(v.Name, func(b *testing.B) {
+			for _, n := range lookupSizes {
+				b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+					lookupFamily.BenchmarkVariant(b, v.Name, lookupBatchOf(n))
+					b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*lookupBatchSize), "ns/lookup")
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkFoldSetBuild is the one-time cost of building every structure
+// from the keys.
+func BenchmarkFoldSetBuild(b *testing.B) {
+	for _, s := range foldSets {
+		b.Run(s.name, func(b *testing.B) {
+			for _, n := range lookupSizes {
+				b.Run(fmt.Sprintf("keys=%d", n), func(b *testing.B) {
+					keys := lookupBatchOf(n).keys
+					b.ReportAllocs()
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						benchutil.AnySink.Store(s.build(keys))
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The archive is a directory with one JSON file per run, named
+//
+//	<date>_<go version>_<commit>_<machine>.json
+//
+// so that a plain ls lists the runs in chronological order.
+
+// gitCommit returns the short hash of HEAD in dir, with a "-dirty" suffix
+// if the work tree has local modifications, or "unknown" outside of git.
+func gitCommit(ctx context.Context, dir string) string {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return "unknown"
+	}
+	commit := strings.TrimSpace(string(out))
+
+	cmd = exec.CommandContext(ctx, "git", "status", "--porcelain", "--untracked-files=no")
+	cmd.Dir = dir
+	if out, err := cmd.Output(); err == nil && len(strings.TrimSpace(string(out))) > 0 {
+		commit += "-dirty"
+	}
+	return commit
+}
+
+// machineName identifies the host a run was made on.
+func machineName() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "unknown"
+	}
+	name, _, _ = strings.Cut(name, ".")
+	return name
+}
+
+// archiveFileName returns the file name set is stored under in the archive.
+func archiveFileName(set *ResultSet) string {
+	clean := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+				return r
+			}
+			return '-'
+		}, s)
+	}
+	return fmt.Sprintf("%s_%s_%s_%s.json",
+		set.Date.UTC().Format("20060102T150405Z"),
+		clean(set.GoVersion), clean(set.Commit), clean(set.Machine))
+}
+
+func archiveResultSet(dir string, set *ResultSet) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, archiveFileName(set))
+	return path, writeFile(path, func(w io.Writer) error { return writeJSON(w, set) })
+}
+
+func readResultSet(path string) (*ResultSet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set ResultSet
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &set, nil
+}
+
+// loadArchive reads every run stored in dir, oldest first.
+func loadArchive(dir string) ([]*ResultSet, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]*ResultSet, 0, len(files))
+	for _, file := range files {
+		set, err := readResultSet(file)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	sort.SliceStable(sets, func(i, j int) bool { return sets[i].Date.Before(sets[j].Date) })
+	return sets, nil
+}
+
+// resolveRun finds the run a user referred to on the command line.
+// ref is either a result file or a Go version, commit or machine name that
+// is looked up in the archive; the latest matching run wins.
+// A non-empty machine additionally restricts the lookup to that machine.
+func resolveRun(archiveDir, ref, machine string) (*ResultSet, error) {
+	if fi, err := os.Stat(ref); err == nil && !fi.IsDir() {
+		return readResultSet(ref)
+	}
+
+	sets, err := loadArchive(archiveDir)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(sets) - 1; i >= 0; i-- {
+		set := sets[i]
+		if machine != "" && set.Machine != machine {
+			continue
+		}
+		if set.GoVersion == ref || set.Machine == ref || strings.HasPrefix(set.Commit, ref) {
+			return set, nil
+		}
+	}
+	return nil, fmt.Errorf("no run matching %q in %s", ref, archiveDir)
+}
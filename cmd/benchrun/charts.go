@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// chart is a self-contained SVG chart of a group of benchmarks.
+//
+// Bar charts have a row per category and a bar per series, a single
+// unnamed series for a plain ranking. Line charts are used for size
+// sweeps and have a point per category, placed on a log scale at X.
+type chart struct {
+	Kind       string // "bar" or "line"
+	Title      string
+	Unit       string
+	File       string // file name inside the chart directory
+	Categories []string
+	X          []float64 // line charts only
+	Series     []chartSeries
+}
+
+type chartSeries struct {
+	Name   string
+	Values []float64 // one per category, NaN if the series has no value for it
+}
+
+// sweepParam matches sub-benchmark names of size sweeps such as
+// "buf=16", "size=1024", "8B", "64KiB" or "8MiB".
+var sweepParam = regexp.MustCompile(`^(?:[A-Za-z_]+=)?(\d+)(?:([KMG])i?B|B)?$`)
+
+func sweepValue(variant string) (float64, bool) {
+	m := sweepParam.FindStringSubmatch(variant)
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	switch m[2] {
+	case "K":
+		v *= 1 << 10
+	case "M":
+		v *= 1 << 20
+	case "G":
+		v *= 1 << 30
+	}
+	return v, true
+}
+
+// buildCharts turns the rankings of a package into charts:
+//
+//   - a ranking whose variants are all sizes becomes a line chart; sibling
+//     sweeps with the same parent share one chart with a line each,
+//   - rankings with the same parent and the same variants, such as the
+//     SQLite/TinySQL pairs of Benchmark_SQLCompare, share a grouped bar chart,
+//   - every other ranking gets a bar chart of its own.
+func buildCharts(pkg string, rankings []Ranking) []*chart {
+	var charts []*chart
+	sweeps := make(map[string]*chart)
+	grouped := make(map[string]*chart)
+
+	// Count which parents have several rankings with identical variants.
+	siblings := make(map[string]int)
+	for _, r := range rankings {
+		if _, ok := asSweep(r); ok {
+			continue
+		}
+		siblings[parentOf(r.Group)+"\x00"+variantKey(r)]++
+	}
+
+	for _, r := range rankings {
+		parent := parentOf(r.Group)
+
+		if xs, ok := asSweep(r); ok {
+			title, series := r.Group, ""
+			if parent != "" {
+				title, series = parent, strings.TrimPrefix(r.Group, parent+"/")
+			}
+			c := sweeps[title]
+			if c == nil {
+				c = &chart{Kind: "line", Title: title, Unit: sweepUnit(r)}
+				sweeps[title] = c
+				charts = append(charts, c)
+			}
+			addSweepSeries(c, series, r, xs)
+			continue
+		}
+
+		if key := parent + "\x00" + variantKey(r); parent != "" && siblings[key] > 1 {
+			c := grouped[key]
+			if c == nil {
+				c = &chart{Kind: "bar", Title: parent, Unit: "ns/op"}
+				for _, e := range r.Entries {
+					c.Series = append(c.Series, chartSeries{Name: e.Variant})
+				}
+				sort.Slice(c.Series, func(i, j int) bool { return c.Series[i].Name < c.Series[j].Name })
+				grouped[key] = c
+				charts = append(charts, c)
+			}
+			c.Categories = append(c.Categories, strings.TrimPrefix(r.Group, parent+"/"))
+			for i := range c.Series {
+				v := math.NaN()
+				for _, e := range r.Entries {
+					if e.Variant == c.Series[i].Name {
+						v = e.Summary.NsPerOp.Median
+					}
+				}
+				c.Series[i].Values = append(c.Series[i].Values, v)
+			}
+			continue
+		}
+
+		title := r.Group
+		if title == "" {
+			title = pkg
+		}
+		c := &chart{Kind: "bar", Title: title, Unit: "ns/op", Series: []chartSeries{{}}}
+		for _, e := range r.Entries {
+			c.Categories = append(c.Categories, e.Variant)
+			c.Series[0].Values = append(c.Series[0].Values, e.Summary.NsPerOp.Median)
+		}
+		charts = append(charts, c)
+	}
+
+	for _, c := range charts {
+		c.File = chartFileName(pkg, c.Title)
+	}
+	return charts
+}
+
+// sweepUnit draws sweeps that report a throughput via b.SetBytes in MB/s,
+// which shows how it grows with the input size better than ns/op.
+func sweepUnit(r Ranking) string {
+	for _, e := range r.Entries {
+		if e.Summary.MBPerSec == 0 {
+			return "ns/op"
+		}
+	}
+	return "MB/s"
+}
+
+// asSweep returns the sizes of a ranking whose variants are all sizes.
+func asSweep(r Ranking) ([]float64, bool) {
+	xs := make([]float64, len(r.Entries))
+	for i, e := range r.Entries {
+		x, ok := sweepValue(e.Variant)
+		if !ok {
+			return nil, false
+		}
+		xs[i] = x
+	}
+	return xs, len(xs) > 1
+}
+
+// addSweepSeries adds the ranking r with the sizes xs as a line to c,
+// merging its sizes into the categories of the chart.
+func addSweepSeries(c *chart, name string, r Ranking, xs []float64) {
+	for i, e := range r.Entries {
+		idx := -1
+		for j, x := range c.X {
+			if x == xs[i] {
+				idx = j
+			}
+		}
+		if idx < 0 {
+			c.X = append(c.X, xs[i])
+			c.Categories = append(c.Categories, e.Variant)
+			for s := range c.Series {
+				c.Series[s].Values = append(c.Series[s].Values, math.NaN())
+			}
+		}
+	}
+
+	// Keep the categories sorted by size.
+	order := make([]int, len(c.X))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return c.X[order[i]] < c.X[order[j]] })
+	c.X = permute(c.X, order)
+	c.Categories = permute(c.Categories, order)
+	for s := range c.Series {
+		c.Series[s].Values = permute(c.Series[s].Values, order)
+	}
+
+	series := chartSeries{Name: name, Values: make([]float64, len(c.X))}
+	for j := range series.Values {
+		series.Values[j] = math.NaN()
+	}
+	for i, e := range r.Entries {
+		v := e.Summary.NsPerOp.Median
+		if c.Unit == "MB/s" {
+			v = e.Summary.MBPerSec
+			if v == 0 {
+				v = math.NaN()
+			}
+		}
+		for j, x := range c.X {
+			if x == xs[i] {
+				series.Values[j] = v
+			}
+		}
+	}
+	c.Series = append(c.Series, series)
+}
+
+func permute[T any](s []T, order []int) []T {
+	out := make([]T, len(order))
+	for i, j := range order {
+		out[i] = s[j]
+	}
+	return out
+}
+
+func parentOf(group string) string {
+	if i := strings.LastIndexByte(group, '/'); i >= 0 {
+		return group[:i]
+	}
+	return ""
+}
+
+func variantKey(r Ranking) string {
+	names := make([]string, len(r.Entries))
+	for i, e := range r.Entries {
+		names[i] = e.Variant
+	}
+	sort.Strings(names)
+	return strings.Join(names, "\x00")
+}
+
+func chartFileName(pkg, title string) string {
+	return fileSafe(pkg+"-"+title) + ".svg"
+}
+
+// fileSafe replaces every character of name that is not safe in file
+// names and URLs with a dash.
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, name)
+}
+
+// writeCharts renders the charts of all packages into dir.
+func writeCharts(dir string, runs []*packageRun) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, r := range runs {
+		for _, c := range r.Charts {
+			svg, err := renderChart(c)
+			if err != nil {
+				return fmt.Errorf("chart %s: %w", c.File, err)
+			}
+			if err := os.WriteFile(filepath.Join(dir, c.File), svg, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
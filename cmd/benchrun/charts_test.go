@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSweepValue(t *testing.T) {
+	tests := map[string]float64{
+		"buf=0":     0,
+		"buf=1024":  1024,
+		"size=8":    8,
+		"8B":        8,
+		"64KiB":     64 << 10,
+		"8MiB":      8 << 20,
+		"1KB":       1 << 10,
+		"workers=4": 4,
+	}
+	for variant, want := range tests {
+		if got, ok := sweepValue(variant); !ok || got != want {
+			t.Errorf("sweepValue(%q) = %v, %v, want %v", variant, got, ok, want)
+		}
+	}
+	for _, variant := range []string{"SQLite", "Strings.Split", "SHA256", "buf=", "x=1y"} {
+		if _, ok := sweepValue(variant); ok {
+			t.Errorf("sweepValue(%q) is a size", variant)
+		}
+	}
+}
+
+func ranking(group string, entries ...any) Ranking {
+	r := Ranking{Group: group}
+	for i := 0; i < len(entries); i += 2 {
+		r.Entries = append(r.Entries, RankEntry{
+			Variant: entries[i].(string),
+			Summary: Summary{NsPerOp: Stats{Median: entries[i+1].(float64)}},
+		})
+	}
+	return r
+}
+
+func TestBuildCharts(t *testing.T) {
+	rankings := []Ranking{
+		ranking("", "BenchmarkCRC32", 137.0, "BenchmarkSHA256", 870.0),
+		ranking("BenchmarkChannelBufferedSizes", "buf=1024", 112.0, "buf=16", 213.0, "buf=0", 521.0),
+		ranking("Benchmark_SQLCompare/SelectJoin", "SQLite", 300.0, "TinySQL", 900.0),
+		ranking("Benchmark_SQLCompare/SelectPoint", "TinySQL", 40.0, "SQLite", 50.0),
+		ranking("BenchmarkHashSizes/MD5", "8B", 30.0, "1KiB", 900.0),
+		ranking("BenchmarkHashSizes/SHA256", "8B", 40.0, "64B", 90.0, "1KiB", 700.0),
+	}
+
+	charts := buildCharts("pkg", rankings)
+	if len(charts) != 4 {
+		t.Fatalf("got %d charts, want 4", len(charts))
+	}
+
+	if c := charts[0]; c.Kind != "bar" || c.Title != "pkg" || len(c.Series) != 1 || strings.Join(c.Categories, ",") != "BenchmarkCRC32,BenchmarkSHA256" {
+		t.Errorf("ranking chart = %+v", c)
+	}
+
+	if c := charts[1]; c.Kind != "line" || strings.Join(c.Categories, ",") != "buf=0,buf=16,buf=1024" || c.Series[0].Values[0] != 521 {
+		t.Errorf("sweep chart = %+v", c)
+	}
+
+	sql := charts[2]
+	if sql.Kind != "bar" || sql.Title != "Benchmark_SQLCompare" || strings.Join(sql.Categories, ",") != "SelectJoin,SelectPoint" {
+		t.Fatalf("grouped chart = %+v", sql)
+	}
+	if sql.Series[0].Name != "SQLite" || sql.Series[0].Values[1] != 50 || sql.Series[1].Values[0] != 900 {
+		t.Errorf("grouped chart series = %+v", sql.Series)
+	}
+
+	sizes := charts[3]
+	if sizes.Kind != "line" || sizes.Title != "BenchmarkHashSizes" || len(sizes.Series) != 2 {
+		t.Fatalf("multi sweep chart = %+v", sizes)
+	}
+	if strings.Join(sizes.Categories, ",") != "8B,64B,1KiB" || !math.IsNaN(sizes.Series[0].Values[1]) || sizes.Series[1].Values[1] != 90 {
+		t.Errorf("multi sweep chart = %+v", sizes)
+	}
+	if sizes.File != "pkg-BenchmarkHashSizes.svg" {
+		t.Errorf("file = %s", sizes.File)
+	}
+
+	for _, c := range charts {
+		svg, err := renderChart(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec := xml.NewDecoder(strings.NewReader(string(svg)))
+		for {
+			if _, err := dec.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Errorf("%s is not well-formed: %v", c.File, err)
+				break
+			}
+		}
+	}
+}
+
+func TestBuildChartsThroughput(t *testing.T) {
+	r := ranking("BenchmarkHashSizes/CRC32", "8B", 10.0, "1KiB", 100.0)
+	r.Entries[0].Summary.MBPerSec = 800
+	r.Entries[1].Summary.MBPerSec = 10240
+	plain := ranking("BenchmarkHashSizes/MD5", "8B", 30.0, "1KiB", 900.0)
+
+	charts := buildCharts("hash", []Ranking{r})
+	if len(charts) != 1 || charts[0].Unit != "MB/s" {
+		t.Fatalf("got %+v, want one MB/s chart", charts)
+	}
+	if got := charts[0].Series[0].Values; got[0] != 800 || got[1] != 10240 {
+		t.Errorf("values = %v, want the throughput", got)
+	}
+	if c := buildCharts("hash", []Ranking{plain})[0]; c.Unit != "ns/op" {
+		t.Errorf("unit without throughput = %s, want ns/op", c.Unit)
+	}
+}
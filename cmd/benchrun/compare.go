@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// comparison is the change of one benchmark between two runs.
+type comparison struct {
+	Key       string // package and benchmark name, e.g. "concat/BenchmarkConcatBuilder"
+	Old, New  []float64
+	OldCenter float64 // median of Old
+	NewCenter float64 // median of New
+	Delta     float64 // relative change of the median, 0.1 means +10%
+	P         float64 // p-value of the Mann-Whitney U test
+	Verdict   string  // "regression", "improvement" or "" if not significant
+}
+
+// runCompare implements `benchrun compare [flags] old new`.
+func runCompare(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	archiveDir := fs.String("archive", "results", "archive to look up runs that are not given as a file")
+	machine := fs.String("machine", "", "only consider archived runs of this machine")
+	metric := fs.String("metric", "ns/op", "metric to compare: ns/op, B/op, allocs/op, MB/s or a custom unit")
+	alpha := fs.Float64("alpha", 0.05, "significance level of the Mann-Whitney U test")
+	threshold := fs.Float64("threshold", 0, "minimum change in percent that is reported as a regression or improvement")
+	fail := fs.Bool("fail", false, "exit with an error if a regression was found")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: benchrun compare [flags] old new\n\n"+
+			"old and new are result files or a Go version, commit or machine\n"+
+			"that is looked up in the archive (latest matching run).\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("compare needs exactly two runs, got %d", fs.NArg())
+	}
+
+	old, err := resolveRun(*archiveDir, fs.Arg(0), *machine)
+	if err != nil {
+		return err
+	}
+	cur, err := resolveRun(*archiveDir, fs.Arg(1), *machine)
+	if err != nil {
+		return err
+	}
+
+	cmps := compareSets(old, cur, *metric, *alpha, *threshold/100)
+	printComparison(stdout, old, cur, *metric, cmps)
+
+	if *fail {
+		for _, c := range cmps {
+			if c.Verdict == "regression" {
+				return fmt.Errorf("%s regressed by %+.2f%%", c.Key, c.Delta*100)
+			}
+		}
+	}
+	return nil
+}
+
+// compareSets pairs the benchmarks present in both runs and tests
+// their samples of metric for a significant change.
+func compareSets(old, cur *ResultSet, metric string, alpha, threshold float64) []comparison {
+	oldSamples := samplesByKey(old, metric)
+	newSamples := samplesByKey(cur, metric)
+
+	var cmps []comparison
+	for key, o := range oldSamples {
+		n, ok := newSamples[key]
+		if !ok {
+			continue
+		}
+		c := comparison{
+			Key:       key,
+			Old:       o,
+			New:       n,
+			OldCenter: median(o),
+			NewCenter: median(n),
+			P:         mannWhitneyU(o, n),
+		}
+		if c.OldCenter != 0 {
+			c.Delta = (c.NewCenter - c.OldCenter) / c.OldCenter
+		}
+		if c.P < alpha && math.Abs(c.Delta) >= threshold && c.Delta != 0 {
+			worse := c.Delta > 0
+			if higherIsBetter(metric) {
+				worse = !worse
+			}
+			if worse {
+				c.Verdict = "regression"
+			} else {
+				c.Verdict = "improvement"
+			}
+		}
+		cmps = append(cmps, c)
+	}
+
+	sort.Slice(cmps, func(i, j int) bool { return cmps[i].Key < cmps[j].Key })
+	return cmps
+}
+
+// samplesByKey groups the values of metric by benchmark. Repeated lines
+// of the same benchmark (go test -count) become multiple samples.
+// The -procs suffix only becomes part of the key if a benchmark was run
+// with several GOMAXPROCS values, so runs from machines with a different
+// core count can still be compared.
+func samplesByKey(set *ResultSet, metric string) map[string][]float64 {
+	procs := make(map[string]map[int]bool)
+	for _, r := range set.Results {
+		name := r.Package + "/" + r.Name
+		if procs[name] == nil {
+			procs[name] = make(map[int]bool)
+		}
+		procs[name][r.Procs] = true
+	}
+
+	samples := make(map[string][]float64)
+	for _, r := range set.Results {
+		v, ok := metricValue(r, metric)
+		if !ok {
+			continue
+		}
+		key := r.Package + "/" + r.Name
+		if len(procs[key]) > 1 {
+			key = fmt.Sprintf("%s-%d", key, r.Procs)
+		}
+		samples[key] = append(samples[key], v)
+	}
+	return samples
+}
+
+func metricValue(r Result, metric string) (float64, bool) {
+	switch metric {
+	case "ns/op":
+		return r.NsPerOp, true
+	case "B/op":
+		return r.BytesPerOp, true
+	case "allocs/op":
+		return r.AllocsPerOp, true
+	case "MB/s":
+		return r.MBPerSec, r.MBPerSec != 0
+	}
+	v, ok := r.Metrics[metric]
+	return v, ok
+}
+
+// higherIsBetter reports whether a larger value of metric is an improvement,
+// as is the case for throughput units.
+func higherIsBetter(metric string) bool {
+	return strings.HasSuffix(metric, "/s")
+}
+
+func printComparison(w io.Writer, old, cur *ResultSet, metric string, cmps []comparison) {
+	fmt.Fprintf(w, "old: %s\n", describeRun(old))
+	fmt.Fprintf(w, "new: %s\n", describeRun(cur))
+	if old.Hardware.Threads > 0 && cur.Hardware.Threads > 0 && !old.Hardware.sameMachine(cur.Hardware) {
+		fmt.Fprintf(w, "warning: the runs were measured on different hardware\n  old: %s\n  new: %s\n", old.Hardware, cur.Hardware)
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "benchmark\told %s\tnew %s\tdelta\t\t\n", metric, metric)
+
+	var regressions, improvements, small int
+	for _, c := range cmps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%+.2f%%\tp=%.3f n=%d+%d\t%s\n",
+			c.Key, formatMetric(c.OldCenter), formatMetric(c.NewCenter),
+			c.Delta*100, c.P, len(c.Old), len(c.New), c.Verdict)
+		switch c.Verdict {
+		case "regression":
+			regressions++
+		case "improvement":
+			improvements++
+		}
+		if len(c.Old) < 4 || len(c.New) < 4 {
+			small++
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d benchmarks compared, %d regressions, %d improvements\n", len(cmps), regressions, improvements)
+	if small > 0 {
+		fmt.Fprintf(w, "%d benchmarks have fewer than 4 samples per run and can never be significant at p<0.05\n", small)
+	}
+}
+
+func describeRun(set *ResultSet) string {
+	return fmt.Sprintf("%s %s/%s, commit %s, machine %s, %s",
+		set.GoVersion, set.GOOS, set.GOARCH, set.Commit, set.Machine, set.Date.Format("2006-01-02 15:04"))
+}
+
+// formatMetric prints v with about four significant digits but without
+// switching to exponent notation for large values. Whole numbers, such
+// as B/op and allocs/op, are printed without decimals.
+func formatMetric(v float64) string {
+	switch a := math.Abs(v); {
+	case a >= 1000 || v == math.Trunc(v):
+		return fmt.Sprintf("%.0f", v)
+	case a >= 100:
+		return fmt.Sprintf("%.1f", v)
+	case a >= 10:
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return fmt.Sprintf("%.3f", v)
+	}
+}
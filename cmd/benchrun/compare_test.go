@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func samples(pkg, name string, procs int, ns ...float64) []Result {
+	rs := make([]Result, len(ns))
+	for i, v := range ns {
+		rs[i] = Result{Package: pkg, Name: name, Procs: procs, NsPerOp: v, MBPerSec: 1e9 / v}
+	}
+	return rs
+}
+
+func TestCompareSets(t *testing.T) {
+	old := &ResultSet{}
+	old.Results = append(old.Results, samples("concat", "BenchmarkConcatBuilder", 12, 100, 101, 99, 100, 102)...)
+	old.Results = append(old.Results, samples("sql", "Benchmark_SQLCompare/SelectPoint/SQLite", 12, 500, 510, 490, 505, 495)...)
+	old.Results = append(old.Results, samples("hash", "BenchmarkMD5", 12, 50, 52, 48, 51, 49)...)
+	old.Results = append(old.Results, samples("hash", "BenchmarkGone", 12, 1)...)
+
+	cur := &ResultSet{}
+	cur.Results = append(cur.Results, samples("concat", "BenchmarkConcatBuilder", 8, 120, 121, 119, 122, 118)...)
+	cur.Results = append(cur.Results, samples("sql", "Benchmark_SQLCompare/SelectPoint/SQLite", 8, 400, 410, 390, 405, 395)...)
+	cur.Results = append(cur.Results, samples("hash", "BenchmarkMD5", 8, 51, 49, 50, 52, 48)...)
+
+	got := make(map[string]string)
+	for _, c := range compareSets(old, cur, "ns/op", 0.05, 0) {
+		got[c.Key] = c.Verdict
+	}
+	want := map[string]string{
+		"concat/BenchmarkConcatBuilder":               "regression",
+		"sql/Benchmark_SQLCompare/SelectPoint/SQLite": "improvement",
+		"hash/BenchmarkMD5":                           "",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("compared %v, want %v", got, want)
+	}
+	for key, verdict := range want {
+		if got[key] != verdict {
+			t.Errorf("%s: verdict %q, want %q", key, got[key], verdict)
+		}
+	}
+
+	// Throughput turns the verdict around.
+	for _, c := range compareSets(old, cur, "MB/s", 0.05, 0) {
+		if c.Key == "concat/BenchmarkConcatBuilder" && c.Verdict != "regression" {
+			t.Errorf("MB/s: verdict %q, want regression", c.Verdict)
+		}
+	}
+
+	// A threshold hides small but significant changes.
+	for _, c := range compareSets(old, cur, "ns/op", 0.05, 0.5) {
+		if c.Verdict != "" {
+			t.Errorf("%s: verdict %q with 50%% threshold, want none", c.Key, c.Verdict)
+		}
+	}
+}
+
+func TestResolveRun(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, set := range []*ResultSet{
+		{Date: base, GoVersion: "go1.26.4", Commit: "aaaaaaa", Machine: "mbp"},
+		{Date: base.Add(time.Hour), GoVersion: "go1.26.4", Commit: "bbbbbbb", Machine: "mbp"},
+		{Date: base.Add(2 * time.Hour), GoVersion: "go1.27.1", Commit: "bbbbbbb", Machine: "linux-box"},
+	} {
+		path, err := archiveResultSet(dir, set)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 && filepath.Base(path) != "20260102T030405Z_go1.26.4_aaaaaaa_mbp.json" {
+			t.Errorf("archive file = %s", filepath.Base(path))
+		}
+	}
+
+	tests := []struct {
+		ref, machine, wantCommit, wantMachine string
+	}{
+		{"go1.26.4", "", "bbbbbbb", "mbp"},
+		{"aaa", "", "aaaaaaa", "mbp"},
+		{"bbbbbbb", "", "bbbbbbb", "linux-box"},
+		{"bbbbbbb", "mbp", "bbbbbbb", "mbp"},
+		{"go1.27.1", "", "bbbbbbb", "linux-box"},
+	}
+	for _, tt := range tests {
+		set, err := resolveRun(dir, tt.ref, tt.machine)
+		if err != nil {
+			t.Errorf("resolveRun(%q, %q): %v", tt.ref, tt.machine, err)
+			continue
+		}
+		if set.Commit != tt.wantCommit || set.Machine != tt.wantMachine {
+			t.Errorf("resolveRun(%q, %q) = %s on %s, want %s on %s", tt.ref, tt.machine, set.Commit, set.Machine, tt.wantCommit, tt.wantMachine)
+		}
+	}
+
+	if _, err := resolveRun(dir, "go1.20", ""); err == nil {
+		t.Error("resolveRun(go1.20) found a run that does not exist")
+	}
+
+	file := filepath.Join(t.TempDir(), "run.json")
+	if err := os.WriteFile(file, []byte(`{"go_version":"go1.25.0"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if set, err := resolveRun(dir, file, ""); err != nil || set.GoVersion != "go1.25.0" {
+		t.Errorf("resolveRun(file) = %v, %v", set, err)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// benchPackage is a directory of the module with at least one Benchmark function.
+type benchPackage struct {
+	Name       string   // slash separated directory relative to the module root, e.g. "hash"
+	Dir        string   // directory on disk
+	Files      []string // *_test.go files of the package, sorted by name
+	Benchmarks []string // top level Benchmark functions in source order
+
+	// Sources holds the source of every benchmark function, including its
+	// doc comment, keyed by function name.
+	Sources map[string]string
+
+	// Parallel holds the benchmarks that call b.RunParallel, directly or
+	// through a function of the package. Only they get a scaling table.
+	Parallel map[string]bool
+}
+
+// discoverPackages walks root and returns every package that declares
+// at least one benchmark, sorted by name.
+// Hidden directories, directories starting with "_", testdata, vendor
+// and nested modules are skipped just like the go tool does.
+func discoverPackages(root string) ([]*benchPackage, error) {
+	var pkgs []*benchPackage
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root {
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" || name == "vendor" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+		}
+
+		pkg, err := parseBenchPackage(path)
+		if err != nil {
+			return err
+		}
+		if pkg == nil {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		pkg.Name = filepath.ToSlash(rel)
+		pkgs = append(pkgs, pkg)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Name < pkgs[j].Name })
+	return pkgs, nil
+}
+
+// parseBenchPackage parses the test files in dir and returns nil
+// if none of them declares a benchmark.
+func parseBenchPackage(dir string) (*benchPackage, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	pkg := &benchPackage{Dir: dir, Sources: make(map[string]string)}
+	fset := token.NewFileSet()
+	var funcs []*ast.FuncDecl
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution|parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		pkg.Files = append(pkg.Files, file)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			funcs = append(funcs, fn)
+			if isBenchmark(fn) {
+				pkg.Benchmarks = append(pkg.Benchmarks, fn.Name.Name)
+				pkg.Sources[fn.Name.Name] = funcSource(fset, src, fn)
+			}
+		}
+	}
+
+	if len(pkg.Benchmarks) == 0 {
+		return nil, nil
+	}
+	pkg.Parallel = make(map[string]bool)
+	for name := range runParallelFuncs(funcs) {
+		if _, ok := pkg.Sources[name]; ok {
+			pkg.Parallel[name] = true
+		}
+	}
+	return pkg, nil
+}
+
+// runParallelFuncs returns the package level functions that call
+// RunParallel or, by name, another function that does.
+func runParallelFuncs(funcs []*ast.FuncDecl) map[string]bool {
+	calls := make(map[string][]string)
+	parallel := make(map[string]bool)
+	for _, fn := range funcs {
+		if fn.Recv != nil || fn.Body == nil {
+			continue
+		}
+		name := fn.Name.Name
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			switch f := call.Fun.(type) {
+			case *ast.Ident:
+				calls[name] = append(calls[name], f.Name)
+			case *ast.SelectorExpr:
+				if f.Sel.Name == "RunParallel" {
+					parallel[name] = true
+				}
+			}
+			return true
+		})
+	}
+	for changed := true; changed; {
+		changed = false
+		for name, callees := range calls {
+			if parallel[name] {
+				continue
+			}
+			for _, callee := range callees {
+				if parallel[callee] {
+					parallel[name], changed = true, true
+					break
+				}
+			}
+		}
+	}
+	return parallel
+}
+
+// funcSource returns the source text of fn, starting at its doc comment.
+func funcSource(fset *token.FileSet, src []byte, fn *ast.FuncDecl) string {
+	start := fn.Pos()
+	if fn.Doc != nil {
+		start = fn.Doc.Pos()
+	}
+	file := fset.File(start)
+	return string(src[file.Offset(start):file.Offset(fn.End())])
+}
+
+// isBenchmark reports whether fn has the shape go test runs as a benchmark:
+// func BenchmarkXxx(b *testing.B) where Xxx does not start with a lower case letter.
+func isBenchmark(fn *ast.FuncDecl) bool {
+	if fn.Recv != nil || fn.Type.TypeParams != nil || fn.Type.Results != nil {
+		return false
+	}
+
+	name, ok := strings.CutPrefix(fn.Name.Name, "Benchmark")
+	if !ok {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(name); unicode.IsLower(r) {
+		return false
+	}
+
+	params := fn.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) > 1 {
+		return false
+	}
+	star, ok := params[0].Type.(*ast.StarExpr)
+	if !ok {
+		return false
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	return sel.Sel.Name == "B"
+}
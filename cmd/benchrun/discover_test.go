@@ -0,0 +1,155 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIsBenchmark(t *testing.T) {
+	src := `package p
+
+import "testing"
+
+func BenchmarkA(b *testing.B)            {}
+func Benchmark(b *testing.B)             {}
+func Benchmark_SQL(b *testing.B)         {}
+func Benchmarkfoo(b *testing.B)          {}
+func BenchmarkNoArg()                    {}
+func BenchmarkT(t *testing.T)            {}
+func BenchmarkResult(b *testing.B) error { return nil }
+func benchmarkHelper(b *testing.B)       {}
+`
+	f, err := parser.ParseFile(token.NewFileSet(), "p_test.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && isBenchmark(fn) {
+			got = append(got, fn.Name.Name)
+		}
+	}
+
+	want := []string{"BenchmarkA", "Benchmark", "Benchmark_SQL"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("benchmarks = %q, want %q", got, want)
+	}
+}
+
+func TestDiscoverPackages(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"a/a_test.go":              "package a\nimport \"testing\"\nfunc BenchmarkA(b *testing.B) {}\n",
+		"b/b_test.go":              "package b\nimport \"testing\"\nfunc TestB(t *testing.T) {}\n",
+		"c/d/d_test.go":            "package d\nimport \"testing\"\nfunc BenchmarkD(b *testing.B) {}\n",
+		"testdata/t_test.go":       "package t\nimport \"testing\"\nfunc BenchmarkT(b *testing.B) {}\n",
+		"nested/go.mod":            "module nested\n",
+		"nested/n_test.go":         "package n\nimport \"testing\"\nfunc BenchmarkN(b *testing.B) {}\n",
+		"_disabled/x_test.go":      "package x\nimport \"testing\"\nfunc BenchmarkX(b *testing.B) {}\n",
+		"a/helper_test.go":         "package a\nimport \"testing\"\nfunc BenchmarkHelper(b *testing.B) {}\n",
+		"a/not_a_test_file.go.txt": "func BenchmarkIgnored(b *testing.B) {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgs, err := discoverPackages(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string][]string)
+	for _, pkg := range pkgs {
+		got[pkg.Name] = pkg.Benchmarks
+	}
+	want := map[string][]string{
+		"a":   {"BenchmarkA", "BenchmarkHelper"},
+		"c/d": {"BenchmarkD"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("packages = %v, want %v", got, want)
+	}
+}
+
+func TestFuncSource(t *testing.T) {
+	dir := t.TempDir()
+	src := `package p
+
+import "testing"
+
+var sink int
+
+// BenchmarkDoc is documented.
+func BenchmarkDoc(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		sink++
+	}
+}
+
+func BenchmarkPlain(b *testing.B) {}
+`
+	if err := os.WriteFile(filepath.Join(dir, "p_test.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := parseBenchPackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"BenchmarkDoc":   "// BenchmarkDoc is documented.\nfunc BenchmarkDoc(b *testing.B) {\n\tfor i := 0; i < b.N; i++ {\n\t\tsink++\n\t}\n}",
+		"BenchmarkPlain": "func BenchmarkPlain(b *testing.B) {}",
+	}
+	if !reflect.DeepEqual(pkg.Sources, want) {
+		t.Errorf("sources = %q, want %q", pkg.Sources, want)
+	}
+}
+
+func TestParallelBenchmarks(t *testing.T) {
+	dir := t.TempDir()
+	src := `package p
+
+import "testing"
+
+func BenchmarkDirect(b *testing.B) {
+	b.Run("sub", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {})
+	})
+}
+
+func BenchmarkHelper(b *testing.B) { runParallel(b) }
+
+func BenchmarkSequential(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+	}
+}
+
+func runParallel(b *testing.B) { b.RunParallel(func(pb *testing.PB) {}) }
+`
+	if err := os.WriteFile(filepath.Join(dir, "p_test.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := parseBenchPackage(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]bool{"BenchmarkDirect": true, "BenchmarkHelper": true}
+	if !reflect.DeepEqual(pkg.Parallel, want) {
+		t.Errorf("parallel = %v, want %v", pkg.Parallel, want)
+	}
+}
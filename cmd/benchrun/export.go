@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// writeFile creates path and hands it to write, closing it afterwards.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeJSON(w io.Writer, set *ResultSet) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(set)
+}
+
+// writeCSV writes one row per result. The run wide fields are repeated on
+// every row and each custom metric unit gets a column of its own, so the
+// file can be loaded into a spreadsheet without further processing.
+func writeCSV(w io.Writer, set *ResultSet) error {
+	units := metricUnits(set.Results)
+
+	header := []string{
+		"go_version", "goos", "goarch", "cpu",
+		"package", "name", "benchmark", "sub", "procs", "iterations",
+		"ns_per_op", "bytes_per_op", "allocs_per_op", "mb_per_s",
+	}
+	header = append(header, units...)
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, r := range set.Results {
+		row := []string{
+			set.GoVersion, set.GOOS, set.GOARCH, set.CPU,
+			r.Package, r.Name, r.Benchmark, r.Sub,
+			strconv.Itoa(r.Procs),
+			strconv.FormatInt(r.Iterations, 10),
+			formatFloat(r.NsPerOp),
+			formatFloat(r.BytesPerOp),
+			formatFloat(r.AllocsPerOp),
+			formatFloat(r.MBPerSec),
+		}
+		for _, unit := range units {
+			if v, ok := r.Metrics[unit]; ok {
+				row = append(row, formatFloat(v))
+			} else {
+				row = append(row, "")
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeSummaryCSV writes one row per benchmark with the statistics
+// of its samples.
+func writeSummaryCSV(w io.Writer, set *ResultSet) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{
+		"go_version", "goos", "goarch", "cpu",
+		"package", "name", "benchmark", "sub", "procs",
+		"samples", "outliers", "median_ns_per_op", "min_ns_per_op", "max_ns_per_op",
+		"ci_low_ns_per_op", "ci_high_ns_per_op", "cv",
+		"bytes_per_op", "allocs_per_op", "mb_per_s", "noisy",
+	})
+	if err != nil {
+		return err
+	}
+	for _, s := range set.Summaries {
+		st := s.NsPerOp
+		row := []string{
+			set.GoVersion, set.GOOS, set.GOARCH, set.CPU,
+			s.Package, s.Name, s.Benchmark, s.Sub,
+			strconv.Itoa(s.Procs),
+			strconv.Itoa(st.Samples),
+			strconv.Itoa(st.Outliers),
+			formatFloat(st.Median),
+			formatFloat(st.Min),
+			formatFloat(st.Max),
+			formatFloat(st.CILow),
+			formatFloat(st.CIHigh),
+			formatFloat(st.CV),
+			formatFloat(s.BytesPerOp),
+			formatFloat(s.AllocsPerOp),
+			formatFloat(s.MBPerSec),
+			strconv.FormatBool(s.Noisy),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// metricUnits returns the sorted set of custom metric units in results.
+func metricUnits(results []Result) []string {
+	seen := make(map[string]bool)
+	var units []string
+	for _, r := range results {
+		for unit := range r.Metrics {
+			if !seen[unit] {
+				seen[unit] = true
+				units = append(units, unit)
+			}
+		}
+	}
+	sort.Strings(units)
+	return units
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
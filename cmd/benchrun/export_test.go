@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV(t *testing.T) {
+	set := &ResultSet{
+		GoVersion: "go1.26.4",
+		GOOS:      "darwin",
+		GOARCH:    "arm64",
+		CPU:       "Apple M2 Max",
+		Results: []Result{
+			{Package: "concat", Name: "BenchmarkConcatBuilder", Benchmark: "BenchmarkConcatBuilder", Procs: 12, Iterations: 100, NsPerOp: 170.3, BytesPerOp: 248, AllocsPerOp: 5},
+			{Package: "sql", Name: "Benchmark_SQLCompare/SelectPoint/SQLite", Benchmark: "Benchmark_SQLCompare", Sub: "SelectPoint/SQLite", Procs: 12, Iterations: 10, NsPerOp: 2000, Metrics: map[string]float64{"rows/op": 1}},
+		},
+	}
+
+	var buf strings.Builder
+	if err := writeCSV(&buf, set); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `go_version,goos,goarch,cpu,package,name,benchmark,sub,procs,iterations,ns_per_op,bytes_per_op,allocs_per_op,mb_per_s,rows/op
+go1.26.4,darwin,arm64,Apple M2 Max,concat,BenchmarkConcatBuilder,BenchmarkConcatBuilder,,12,100,170.3,248,5,0,
+go1.26.4,darwin,arm64,Apple M2 Max,sql,Benchmark_SQLCompare/SelectPoint/SQLite,Benchmark_SQLCompare,SelectPoint/SQLite,12,10,2000,0,0,0,1
+`
+	if buf.String() != want {
+		t.Errorf("csv =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+)
+
+const (
+	flameWidth    = 1200.0
+	flameRow      = 16.0
+	flameMinWidth = 0.5 // frames narrower than this many pixels are dropped
+)
+
+// flameNode is a frame of the flamegraph, its value includes all callees.
+type flameNode struct {
+	name     string
+	value    int64
+	children map[string]*flameNode
+}
+
+func (n *flameNode) child(name string) *flameNode {
+	c := n.children[name]
+	if c == nil {
+		c = &flameNode{name: name, children: make(map[string]*flameNode)}
+		n.children[name] = c
+	}
+	return c
+}
+
+// sortedChildren returns the callees in alphabetical order, as flamegraphs
+// do, so identical stacks line up across profiles.
+func (n *flameNode) sortedChildren() []*flameNode {
+	cs := make([]*flameNode, 0, len(n.children))
+	for _, c := range n.children {
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].name < cs[j].name })
+	return cs
+}
+
+// renderFlamegraph draws the stacks of p for the sample type vi as a
+// self-contained SVG flamegraph: the root at the bottom, callees on top
+// of their callers and widths proportional to the value. Every frame has
+// a tooltip with its value and share.
+func renderFlamegraph(p *profile, vi int, title string) []byte {
+	root := &flameNode{name: "all", children: make(map[string]*flameNode)}
+	depth := 0
+	for _, s := range p.Samples {
+		if vi >= len(s.Values) || s.Values[vi] == 0 {
+			continue
+		}
+		v := s.Values[vi]
+		root.value += v
+		n := root
+		for i := len(s.Stack) - 1; i >= 0; i-- {
+			n = n.child(s.Stack[i])
+			n.value += v
+		}
+		depth = max(depth, len(s.Stack))
+	}
+	unit := p.SampleTypes[vi].Unit
+
+	const top, bottom = 40.0, 10.0
+	height := top + float64(depth+1)*flameRow + bottom
+	scale := 0.0
+	if root.value > 0 {
+		scale = (flameWidth - 20) / float64(root.value)
+	}
+
+	var buf bytes.Buffer
+	svgHeader(&buf, flameWidth, height, title)
+
+	var draw func(n *flameNode, x float64, level int)
+	draw = func(n *flameNode, x float64, level int) {
+		w := float64(n.value) * scale
+		if w < flameMinWidth {
+			return
+		}
+		y := height - bottom - float64(level+1)*flameRow
+		tip := fmt.Sprintf("%s: %s (%.2f%%)", n.name, formatProfileValue(n.value, unit), share(n.value, root.value)*100)
+		fmt.Fprintf(&buf, `<g><title>%s</title><rect x="%.1f" y="%.1f" width="%.1f" height="%.0f" fill="%s" rx="2"/>`,
+			escape(tip), x, y, w, flameRow-1, flameColor(n.name))
+		if chars := int((w - 6) / chartCharWide); chars >= 3 {
+			label := n.name
+			if len(label) > chars {
+				label = label[:chars-2] + ".."
+			}
+			fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" font-size="11">%s</text>`, x+3, y+flameRow-4, escape(label))
+		}
+		buf.WriteString("</g>\n")
+		for _, c := range n.sortedChildren() {
+			draw(c, x, level+1)
+			x += float64(c.value) * scale
+		}
+	}
+	draw(root, 10, 0)
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// flameColor derives a stable warm color from the function name.
+func flameColor(name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	v := h.Sum32()
+	return fmt.Sprintf("rgb(%d,%d,%d)", 205+v%50, 80+(v>>8)%150, (v>>16)%60)
+}
+
+// formatProfileValue prints a profile value in its unit.
+func formatProfileValue(v int64, unit string) string {
+	switch unit {
+	case "nanoseconds":
+		return time.Duration(v).String()
+	case "bytes":
+		return formatBytes(v)
+	}
+	return fmt.Sprintf("%d %s", v, unit)
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// goEnv describes the toolchain the benchmarks were run with.
+type goEnv struct {
+	GoVersion string `json:"GOVERSION"` // e.g. "go1.26.4"
+	GOOS      string `json:"GOOS"`
+	GOARCH    string `json:"GOARCH"`
+	GOAMD64   string `json:"GOAMD64"` // microarchitecture level, amd64 only
+	GOARM64   string `json:"GOARM64"` // microarchitecture level, arm64 only
+}
+
+func goEnvironment(ctx context.Context, goCmd, dir string) (goEnv, error) {
+	cmd := exec.CommandContext(ctx, goCmd, "env", "-json", "GOVERSION", "GOOS", "GOARCH", "GOAMD64", "GOARM64")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return goEnv{}, fmt.Errorf("go env: %w", err)
+	}
+
+	var env goEnv
+	if err := json.Unmarshal(out, &env); err != nil {
+		return goEnv{}, fmt.Errorf("go env: %w", err)
+	}
+	return env, nil
+}
+
+// testEvent is a single line of `go test -json` output, see `go doc test2json`.
+type testEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Output  string
+}
+
+// packageRun is the outcome of benchmarking a single package.
+type packageRun struct {
+	*benchPackage
+	ImportPath string
+	CPU        string    // cpu model as reported by go test
+	Output     string    // go test output without the -json/-v framing
+	Results    []Result  // parsed benchmark lines of Output
+	Summaries  []Summary // statistics of Results per benchmark
+	Rankings   []Ranking // variants of Summaries, fastest first
+	Charts     []*chart  // charts of Rankings
+	Profiles   []*profileSummary
+}
+
+// goTestArgs returns the go test arguments used for every package,
+// without -json and the package path.
+func goTestArgs(opts options) []string {
+	args := []string{"test", "-bench", opts.bench, "-benchmem"}
+	if opts.count > 1 {
+		args = append(args, "-count", strconv.Itoa(opts.count))
+	}
+	if opts.benchtime != "" {
+		args = append(args, "-benchtime", opts.benchtime)
+	}
+	if opts.cpu != "" {
+		args = append(args, "-cpu", opts.cpu)
+	}
+	return args
+}
+
+// goTestCommand is the command line a reader can use to reproduce a run.
+func goTestCommand(opts options) string {
+	return "go " + strings.Join(goTestArgs(opts), " ")
+}
+
+func runPackage(ctx context.Context, opts options, pkg *benchPackage) (*packageRun, error) {
+	args := append(goTestArgs(opts), profileArgs(opts.profileDir, opts.profiles, pkg.Name)...)
+	args = append(args, "-json", "./"+pkg.Name)
+
+	cmd := exec.CommandContext(ctx, opts.goCmd, args...)
+	cmd.Dir = opts.root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("%s: %w", pkg.Name, err)
+	}
+
+	r, parseErr := parseTestEvents(stdout)
+	waitErr := cmd.Wait()
+	if parseErr != nil {
+		return nil, fmt.Errorf("%s: %w", pkg.Name, parseErr)
+	}
+	if waitErr != nil {
+		return nil, fmt.Errorf("%s: go test failed: %w\n%s%s", pkg.Name, waitErr, r.Output, stderr.String())
+	}
+
+	r.benchPackage = pkg
+	for i := range r.Results {
+		r.Results[i].Package = pkg.Name
+	}
+	return r, nil
+}
+
+// parseTestEvents reads a test2json stream, reassembles the plain
+// go test output, the way it looks without -json, and parses its
+// benchmark result lines.
+func parseTestEvents(rd io.Reader) (*packageRun, error) {
+	r := &packageRun{}
+	var out strings.Builder
+	benchNames := make(map[string]bool)
+
+	dec := json.NewDecoder(rd)
+	for {
+		var ev testEvent
+		if err := dec.Decode(&ev); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if r.ImportPath == "" {
+			r.ImportPath = ev.Package
+		}
+		if ev.Action == "run" && strings.HasPrefix(ev.Test, "Benchmark") {
+			benchNames[ev.Test] = true
+		}
+		if ev.Action == "output" {
+			out.WriteString(ev.Output)
+		}
+	}
+
+	var buf strings.Builder
+	sc := bufio.NewScanner(strings.NewReader(out.String()))
+	sc.Buffer(nil, 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if isVerboseFraming(line, benchNames) {
+			continue
+		}
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+
+		if cpu, ok := strings.CutPrefix(line, "cpu: "); ok {
+			r.CPU = cpu
+		} else if res, ok := parseBenchLine(line, benchNames); ok {
+			r.Results = append(r.Results, res)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	r.Output = buf.String()
+	return r, nil
+}
+
+// isVerboseFraming reports whether line only exists because go test -json
+// implies -v: test start/pass markers and the bare benchmark name printed
+// before each benchmark result.
+func isVerboseFraming(line string, benchNames map[string]bool) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"=== RUN", "=== PAUSE", "=== CONT", "=== NAME", "--- PASS:", "--- SKIP:"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return benchNames[line]
+}
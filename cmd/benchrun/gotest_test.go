@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTestEvents(t *testing.T) {
+	const pkg = "github.com/SimonWaldherr/golang-benchmarks/split"
+	stream := `{"Action":"start","Package":"` + pkg + `"}
+{"Action":"output","Package":"` + pkg + `","Output":"goos: linux\n"}
+{"Action":"output","Package":"` + pkg + `","Output":"pkg: ` + pkg + `\n"}
+{"Action":"run","Package":"` + pkg + `","Test":"BenchmarkSplitMethods"}
+{"Action":"output","Package":"` + pkg + `","Test":"BenchmarkSplitMethods","Output":"=== RUN   BenchmarkSplitMethods\n"}
+{"Action":"output","Package":"` + pkg + `","Test":"BenchmarkSplitMethods","Output":"BenchmarkSplitMethods\n"}
+{"Action":"run","Package":"` + pkg + `","Test":"BenchmarkSplitMethods/Strings.Split"}
+{"Action":"output","Package":"` + pkg + `","Test":"BenchmarkSplitMethods/Strings.Split","Output":"=== RUN   BenchmarkSplitMethods/Strings.Split\n"}
+{"Action":"output","Package":"` + pkg + `","Test":"BenchmarkSplitMethods/Strings.Split","Output":"BenchmarkSplitMethods/Strings.Split\n"}
+{"Action":"output","Package":"` + pkg + `","Test":"BenchmarkSplitMethods/Strings.Split","Output":"BenchmarkSplitMethods/Strings.Split-8   \t"}
+{"Action":"output","Package":"` + pkg + `","Test":"BenchmarkSplitMethods/Strings.Split","Output":"  100\t  187.1 ns/op\t  80 B/op\t  1 allocs/op\n"}
+{"Action":"output","Package":"` + pkg + `","Output":"PASS\n"}
+{"Action":"pass","Package":"` + pkg + `"}
+`
+
+	r, err := parseTestEvents(strings.NewReader(stream))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ImportPath != pkg {
+		t.Errorf("import path = %q, want %q", r.ImportPath, pkg)
+	}
+
+	want := "goos: linux\n" +
+		"pkg: " + pkg + "\n" +
+		"BenchmarkSplitMethods/Strings.Split-8   \t  100\t  187.1 ns/op\t  80 B/op\t  1 allocs/op\n" +
+		"PASS\n"
+	if r.Output != want {
+		t.Errorf("output =\n%s\nwant\n%s", r.Output, want)
+	}
+}
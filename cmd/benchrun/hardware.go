@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// Hardware fingerprints the machine a result set was measured on, so
+// numbers from different machines can be told apart. Fields that could
+// not be detected on the platform are left empty.
+type Hardware struct {
+	CPUModel       string  `json:"cpu_model"`
+	Cores          int     `json:"cores,omitempty"` // physical cores
+	Threads        int     `json:"threads"`         // logical CPUs
+	Caches         []Cache `json:"caches,omitempty"`
+	MemoryBytes    uint64  `json:"memory_bytes,omitempty"`
+	Kernel         string  `json:"kernel,omitempty"`
+	GOMAXPROCS     int     `json:"gomaxprocs"`
+	ArchLevel      string  `json:"arch_level,omitempty"` // e.g. "GOAMD64=v3" or "GOARM64=v8.0"
+	Governor       string  `json:"governor,omitempty"`   // cpufreq scaling governor
+	Virtualization string  `json:"virtualization,omitempty"`
+}
+
+// Cache is one CPU cache level as seen by a single core.
+type Cache struct {
+	Level     int    `json:"level"`
+	Type      string `json:"type"` // "Data", "Instruction" or "Unified"
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// detectHardware fingerprints the current machine. The benchmarks run in
+// child processes with the same environment, so GOMAXPROCS of this process
+// is the one they see.
+func detectHardware(env goEnv) Hardware {
+	hw := platformHardware()
+	if hw.Threads == 0 {
+		hw.Threads = runtime.NumCPU()
+	}
+	hw.GOMAXPROCS = runtime.GOMAXPROCS(0)
+	hw.ArchLevel = env.archLevel()
+	return hw
+}
+
+// archLevel returns the microarchitecture level the test binaries were
+// compiled for.
+func (e goEnv) archLevel() string {
+	switch {
+	case e.GOARCH == "amd64" && e.GOAMD64 != "":
+		return "GOAMD64=" + e.GOAMD64
+	case e.GOARCH == "arm64" && e.GOARM64 != "":
+		return "GOARM64=" + e.GOARM64
+	}
+	return ""
+}
+
+// String summarizes the fingerprint on a single line for the README.
+func (hw Hardware) String() string {
+	var parts []string
+	add := func(format string, args ...any) {
+		parts = append(parts, fmt.Sprintf(format, args...))
+	}
+
+	if hw.CPUModel != "" {
+		add("%s", hw.CPUModel)
+	}
+	if hw.Cores > 0 {
+		add("%d cores, %d threads", hw.Cores, hw.Threads)
+	} else if hw.Threads > 0 {
+		add("%d threads", hw.Threads)
+	}
+	if len(hw.Caches) > 0 {
+		caches := make([]string, len(hw.Caches))
+		for i, c := range hw.Caches {
+			caches[i] = c.Name() + " " + formatBytes(c.SizeBytes)
+		}
+		add("%s", strings.Join(caches, ", "))
+	}
+	if hw.MemoryBytes > 0 {
+		add("%s RAM", formatBytes(int64(hw.MemoryBytes)))
+	}
+	if hw.Kernel != "" {
+		add("%s", hw.Kernel)
+	}
+	add("GOMAXPROCS=%d", hw.GOMAXPROCS)
+	if hw.ArchLevel != "" {
+		add("%s", hw.ArchLevel)
+	}
+	if hw.Governor != "" {
+		add("%s governor", hw.Governor)
+	}
+	if hw.Virtualization != "" {
+		add("virtualized (%s)", hw.Virtualization)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// Name returns the usual short name of the cache, such as "L1d" or "L3".
+func (c Cache) Name() string {
+	name := fmt.Sprintf("L%d", c.Level)
+	switch c.Type {
+	case "Data":
+		name += "d"
+	case "Instruction":
+		name += "i"
+	}
+	return name
+}
+
+// sameMachine reports whether two fingerprints describe the same kind of
+// machine. Kernel and governor updates are not considered a change.
+func (hw Hardware) sameMachine(other Hardware) bool {
+	return hw.CPUModel == other.CPUModel &&
+		hw.Threads == other.Threads &&
+		hw.MemoryBytes == other.MemoryBytes &&
+		hw.Virtualization == other.Virtualization
+}
+
+func formatBytes(n int64) string {
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	v, i := float64(n), 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+	if v == float64(int64(v)) {
+		return fmt.Sprintf("%.0f %s", v, units[i])
+	}
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
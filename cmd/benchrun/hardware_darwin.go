@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+func platformHardware() Hardware {
+	var hw Hardware
+	hw.CPUModel = sysctl("machdep.cpu.brand_string")
+	hw.Cores, _ = strconv.Atoi(sysctl("hw.physicalcpu"))
+	hw.Threads, _ = strconv.Atoi(sysctl("hw.logicalcpu"))
+	hw.MemoryBytes, _ = strconv.ParseUint(sysctl("hw.memsize"), 10, 64)
+	if release := sysctl("kern.osrelease"); release != "" {
+		hw.Kernel = "Darwin " + release
+	}
+	for _, c := range []struct {
+		name  string
+		level int
+		typ   string
+	}{
+		{"hw.l1dcachesize", 1, "Data"},
+		{"hw.l1icachesize", 1, "Instruction"},
+		{"hw.l2cachesize", 2, "Unified"},
+		{"hw.l3cachesize", 3, "Unified"},
+	} {
+		if size, err := strconv.ParseInt(sysctl(c.name), 10, 64); err == nil && size > 0 {
+			hw.Caches = append(hw.Caches, Cache{Level: c.level, Type: c.typ, SizeBytes: size})
+		}
+	}
+	if sysctl("kern.hv_vmm_present") == "1" {
+		hw.Virtualization = "unknown hypervisor"
+	}
+	return hw
+}
+
+func sysctl(name string) string {
+	out, err := exec.Command("sysctl", "-n", name).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+func platformHardware() Hardware {
+	return linuxHardware(os.DirFS("/"))
+}
+
+// linuxHardware reads the fingerprint from /proc and /sys of the file
+// system root fsys.
+func linuxHardware(fsys fs.FS) Hardware {
+	var hw Hardware
+
+	cores := make(map[string]bool)
+	var physical string
+	hypervisor := false
+	scanKeyValues(fsys, "proc/cpuinfo", func(key, value string) {
+		switch key {
+		case "processor":
+			hw.Threads++
+		case "model name", "Hardware":
+			if hw.CPUModel == "" {
+				hw.CPUModel = value
+			}
+		case "physical id":
+			physical = value
+		case "core id":
+			cores[physical+"/"+value] = true
+		case "flags":
+			hypervisor = hypervisor || strings.Contains(" "+value+" ", " hypervisor ")
+		}
+	})
+	if topo := sysfsCores(fsys); topo > 0 {
+		hw.Cores = topo
+	} else {
+		hw.Cores = len(cores)
+	}
+
+	scanKeyValues(fsys, "proc/meminfo", func(key, value string) {
+		if key == "MemTotal" {
+			kb, _ := strconv.ParseUint(strings.TrimSuffix(value, " kB"), 10, 64)
+			hw.MemoryBytes = kb << 10
+		}
+	})
+
+	if release := readLine(fsys, "proc/sys/kernel/osrelease"); release != "" {
+		hw.Kernel = "Linux " + release
+	}
+	hw.Governor = readLine(fsys, "sys/devices/system/cpu/cpu0/cpufreq/scaling_governor")
+	hw.Caches = sysfsCaches(fsys)
+	hw.Virtualization = linuxVirtualization(fsys, hypervisor)
+	return hw
+}
+
+// sysfsCores counts the distinct (package, core) pairs of all CPUs.
+func sysfsCores(fsys fs.FS) int {
+	dirs, _ := fs.Glob(fsys, "sys/devices/system/cpu/cpu[0-9]*")
+	cores := make(map[string]bool)
+	for _, dir := range dirs {
+		pkg := readLine(fsys, path.Join(dir, "topology/physical_package_id"))
+		core := readLine(fsys, path.Join(dir, "topology/core_id"))
+		if core != "" {
+			cores[pkg+"/"+core] = true
+		}
+	}
+	return len(cores)
+}
+
+// sysfsCaches returns the caches of the first CPU, smallest level first.
+func sysfsCaches(fsys fs.FS) []Cache {
+	dirs, _ := fs.Glob(fsys, "sys/devices/system/cpu/cpu0/cache/index[0-9]*")
+	var caches []Cache
+	for _, dir := range dirs {
+		level, err := strconv.Atoi(readLine(fsys, path.Join(dir, "level")))
+		if err != nil {
+			continue
+		}
+		size, ok := parseCacheSize(readLine(fsys, path.Join(dir, "size")))
+		if !ok {
+			continue
+		}
+		caches = append(caches, Cache{Level: level, Type: readLine(fsys, path.Join(dir, "type")), SizeBytes: size})
+	}
+	return caches
+}
+
+// parseCacheSize parses sizes such as "48K" or "2048K" as used by sysfs.
+func parseCacheSize(s string) (int64, bool) {
+	shift := 0
+	switch {
+	case strings.HasSuffix(s, "K"):
+		shift = 10
+	case strings.HasSuffix(s, "M"):
+		shift = 20
+	}
+	n, err := strconv.ParseInt(strings.TrimRight(s, "KM"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n << shift, true
+}
+
+// dmiHypervisors maps DMI vendor and product names to the hypervisor.
+var dmiHypervisors = []struct{ match, name string }{
+	{"KVM", "KVM"},
+	{"QEMU", "QEMU"},
+	{"VMware", "VMware"},
+	{"VirtualBox", "VirtualBox"},
+	{"Xen", "Xen"},
+	{"Virtual Machine", "Hyper-V"},
+	{"Amazon EC2", "Amazon EC2"},
+	{"Google Compute Engine", "Google Compute Engine"},
+	{"Parallels", "Parallels"},
+}
+
+// linuxVirtualization names the hypervisor the machine runs under, or
+// returns "" on bare metal. hypervisor is the cpuinfo flag, which is set
+// by every common hypervisor even when it does not identify itself.
+func linuxVirtualization(fsys fs.FS, hypervisor bool) string {
+	if t := readLine(fsys, "sys/hypervisor/type"); t != "" {
+		return t
+	}
+	dmi := readLine(fsys, "sys/class/dmi/id/sys_vendor") + " " + readLine(fsys, "sys/class/dmi/id/product_name")
+	for _, h := range dmiHypervisors {
+		if strings.Contains(dmi, h.match) {
+			return h.name
+		}
+	}
+	if hypervisor {
+		return "unknown hypervisor"
+	}
+	return ""
+}
+
+// scanKeyValues calls fn for every "key: value" line of a /proc file.
+func scanKeyValues(fsys fs.FS, name string, fn func(key, value string)) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return
+	}
+	s := bufio.NewScanner(bytes.NewReader(b))
+	for s.Scan() {
+		key, value, ok := strings.Cut(s.Text(), ":")
+		if ok {
+			fn(strings.TrimSpace(key), strings.TrimSpace(value))
+		}
+	}
+}
+
+func readLine(fsys fs.FS, name string) string {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return ""
+	}
+	line, _, _ := strings.Cut(string(b), "\n")
+	return strings.TrimSpace(line)
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLinuxHardware(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+	fsys := fstest.MapFS{
+		"proc/cpuinfo": file("processor\t: 0\nmodel name\t: Intel(R) Xeon(R) Processor\nphysical id\t: 0\ncore id\t\t: 0\nflags\t\t: fpu sse2 hypervisor avx2\n\n" +
+			"processor\t: 1\nmodel name\t: Intel(R) Xeon(R) Processor\nphysical id\t: 0\ncore id\t\t: 0\nflags\t\t: fpu sse2 hypervisor avx2\n"),
+		"proc/meminfo":              file("MemTotal:       16303376 kB\nMemFree:         1234 kB\n"),
+		"proc/sys/kernel/osrelease": file("6.8.0-45-generic\n"),
+		"sys/devices/system/cpu/cpu0/topology/physical_package_id": file("0\n"),
+		"sys/devices/system/cpu/cpu0/topology/core_id":             file("0\n"),
+		"sys/devices/system/cpu/cpu1/topology/physical_package_id": file("0\n"),
+		"sys/devices/system/cpu/cpu1/topology/core_id":             file("0\n"),
+		"sys/devices/system/cpu/cpu0/cpufreq/scaling_governor":     file("performance\n"),
+		"sys/devices/system/cpu/cpu0/cache/index0/level":           file("1\n"),
+		"sys/devices/system/cpu/cpu0/cache/index0/type":            file("Data\n"),
+		"sys/devices/system/cpu/cpu0/cache/index0/size":            file("48K\n"),
+		"sys/devices/system/cpu/cpu0/cache/index1/level":           file("3\n"),
+		"sys/devices/system/cpu/cpu0/cache/index1/type":            file("Unified\n"),
+		"sys/devices/system/cpu/cpu0/cache/index1/size":            file("107520K\n"),
+		"sys/class/dmi/id/sys_vendor":                              file("QEMU\n"),
+		"sys/class/dmi/id/product_name":                            file("Standard PC (Q35 + ICH9, 2009)\n"),
+	}
+
+	got := linuxHardware(fsys)
+	want := Hardware{
+		CPUModel:    "Intel(R) Xeon(R) Processor",
+		Cores:       1,
+		Threads:     2,
+		Caches:      []Cache{{Level: 1, Type: "Data", SizeBytes: 48 << 10}, {Level: 3, Type: "Unified", SizeBytes: 107520 << 10}},
+		MemoryBytes: 16303376 << 10,
+		Kernel:      "Linux 6.8.0-45-generic",
+		Governor:    "performance",
+
+		Virtualization: "QEMU",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("linuxHardware =\n%+v\nwant\n%+v", got, want)
+	}
+
+	delete(fsys, "sys/class/dmi/id/sys_vendor")
+	delete(fsys, "sys/class/dmi/id/product_name")
+	if got := linuxHardware(fsys).Virtualization; got != "unknown hypervisor" {
+		t.Errorf("virtualization from the cpuinfo flag = %q", got)
+	}
+}
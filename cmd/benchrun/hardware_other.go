@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package main
+
+// platformHardware only detects what the runtime knows on other systems.
+func platformHardware() Hardware {
+	return Hardware{}
+}
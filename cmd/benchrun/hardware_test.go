@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestHardwareString(t *testing.T) {
+	hw := Hardware{
+		CPUModel:       "Apple M2 Max",
+		Cores:          12,
+		Threads:        12,
+		Caches:         []Cache{{Level: 1, Type: "Data", SizeBytes: 64 << 10}, {Level: 2, Type: "Unified", SizeBytes: 4 << 20}},
+		MemoryBytes:    32 << 30,
+		Kernel:         "Darwin 24.1.0",
+		GOMAXPROCS:     12,
+		ArchLevel:      "GOARM64=v8.0",
+		Virtualization: "unknown hypervisor",
+	}
+	want := "Apple M2 Max · 12 cores, 12 threads · L1d 64 KiB, L2 4 MiB · 32 GiB RAM · Darwin 24.1.0 · GOMAXPROCS=12 · GOARM64=v8.0 · virtualized (unknown hypervisor)"
+	if got := hw.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestArchLevel(t *testing.T) {
+	tests := []struct {
+		env  goEnv
+		want string
+	}{
+		{goEnv{GOARCH: "amd64", GOAMD64: "v3", GOARM64: "v8.0"}, "GOAMD64=v3"},
+		{goEnv{GOARCH: "arm64", GOAMD64: "v1", GOARM64: "v8.0"}, "GOARM64=v8.0"},
+		{goEnv{GOARCH: "riscv64"}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.env.archLevel(); got != tt.want {
+			t.Errorf("%+v.archLevel() = %q, want %q", tt.env, got, tt.want)
+		}
+	}
+}
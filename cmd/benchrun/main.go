@@ -0,0 +1,262 @@
+// Command benchrun discovers every package of this module that contains
+// Benchmark functions, runs them via `go test -bench -benchmem -json`
+// and regenerates README.md from a template.
+//
+// It replaces the hand-maintained package list of updateBenchLogs.sh,
+// so a new benchmark package shows up in the README as soon as it exists.
+// Every benchmark is sampled -count times; the README and the exported data
+// show the median, range, 95% confidence interval and coefficient of
+// variation of the samples, after outliers were rejected.
+// Groups of variants are ranked and drawn as SVG charts, size sweeps such
+// as buf=16 or 64KiB as log-scale line charts.
+// A static HTML site with a page per package, sortable tables, the source
+// of every benchmark and its history across archived runs is written to
+// the -report directory.
+// Every result set is stamped with a fingerprint of the machine: CPU model,
+// cores, caches, RAM, kernel, GOMAXPROCS, GOAMD64/GOARM64, CPU governor and
+// whether it runs virtualized.
+// With -profile cpu,mem,mutex,block the profiles of every package are
+// captured as well; the report lists their hottest functions and shows a
+// flamegraph of each.
+// With -cpu 1,2,4,8 every benchmark runs at each GOMAXPROCS; benchmarks
+// measured at several values get a scaling table with the throughput per
+// core and the parallel efficiency, and those that stop scaling are flagged.
+// With -json, -csv and -summary-csv the results are additionally exported
+// in a machine-readable form.
+//
+//	go run ./cmd/benchrun
+//	go run ./cmd/benchrun -pkg hash,concat -benchtime 100ms -o /tmp/README.md
+//	go run ./cmd/benchrun -json results.json -csv results.csv
+//
+// Every run is also stored in the results archive, one file per run keyed by
+// Go version, commit and machine. The compare sub command tests two runs for
+// significant regressions and improvements:
+//
+//	go run ./cmd/benchrun compare go1.26.4 go1.27.1
+//	go run ./cmd/benchrun compare -metric allocs/op results/old.json results/new.json
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("benchrun: ")
+
+	if len(os.Args) > 1 && os.Args[1] == "compare" {
+		if err := runCompare(os.Args[2:], os.Stdout); err != nil {
+			if err == flag.ErrHelp {
+				return
+			}
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var opts options
+	flag.StringVar(&opts.root, "root", ".", "module root to scan for benchmark packages")
+	flag.StringVar(&opts.goCmd, "go", "go", "go command used to run the benchmarks")
+	flag.StringVar(&opts.bench, "bench", ".", "value passed to go test -bench")
+	flag.StringVar(&opts.benchtime, "benchtime", "", "value passed to go test -benchtime (empty uses the go test default)")
+	flag.StringVar(&opts.cpu, "cpu", "", "value passed to go test -cpu, e.g. 1,2,4,8 for the scaling tables")
+	flag.IntVar(&opts.count, "count", 5, "number of samples taken of every benchmark (go test -count)")
+	flag.Float64Var(&opts.maxCV, "maxcv", 0.05, "coefficient of variation above which a result is marked as noisy")
+	flag.StringVar(&opts.pkgs, "pkg", "", "comma separated list of package directories to run (default: all discovered)")
+	flag.StringVar(&opts.readme, "o", "README.md", "README file to write")
+	flag.StringVar(&opts.charts, "charts", "charts", "directory the SVG charts are written to, referenced from the README (empty disables charts)")
+	flag.StringVar(&opts.template, "template", "", "README template to use instead of the built-in one")
+	flag.StringVar(&opts.jsonOut, "json", "", "write the results as JSON to this file")
+	flag.StringVar(&opts.csvOut, "csv", "", "write the results as CSV to this file")
+	flag.StringVar(&opts.summaryCSV, "summary-csv", "", "write the per benchmark statistics as CSV to this file")
+	flag.StringVar(&opts.archive, "archive", "results", "directory the results of every run are stored in (empty disables the archive)")
+	flag.StringVar(&opts.report, "report", "report", "directory the HTML report is written to (empty disables the report)")
+	profile := flag.String("profile", "", "comma separated profiles to capture per package: cpu, mem, mutex, block")
+	flag.StringVar(&opts.profileDir, "profile-dir", "profiles", "directory the profiles, test binaries and flamegraphs are written to")
+	flag.IntVar(&opts.profileTop, "top", 15, "number of hot functions of each profile shown in the report")
+	flag.BoolVar(&opts.list, "list", false, "only list the discovered packages and benchmarks")
+	flag.Parse()
+
+	var err error
+	if opts.profiles, err = parseProfileKinds(*profile); err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type options struct {
+	root       string
+	goCmd      string
+	bench      string
+	benchtime  string
+	cpu        string
+	count      int
+	maxCV      float64
+	pkgs       string
+	readme     string
+	charts     string
+	template   string
+	jsonOut    string
+	csvOut     string
+	summaryCSV string
+	archive    string
+	report     string
+	profiles   []string // parsed -profile
+	profileDir string
+	profileTop int
+	list       bool
+}
+
+func run(ctx context.Context, opts options) error {
+	pkgs, err := discoverPackages(opts.root)
+	if err != nil {
+		return err
+	}
+	pkgs, err = filterPackages(pkgs, opts.pkgs)
+	if err != nil {
+		return err
+	}
+
+	if opts.list {
+		for _, pkg := range pkgs {
+			fmt.Printf("%s\t%s\n", pkg.Name, strings.Join(pkg.Benchmarks, " "))
+		}
+		return nil
+	}
+
+	env, err := goEnvironment(ctx, opts.goCmd, opts.root)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.profiles) > 0 {
+		if opts.profileDir, err = filepath.Abs(opts.profileDir); err != nil {
+			return err
+		}
+		if err := os.MkdirAll(opts.profileDir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	runs := make([]*packageRun, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		log.Printf("running %s (%d benchmarks)", pkg.Name, len(pkg.Benchmarks))
+		r, err := runPackage(ctx, opts, pkg)
+		if err != nil {
+			return err
+		}
+		r.Summaries = summarize(r.Results, opts.maxCV)
+		r.Rankings = rankSummaries(r.Summaries)
+		r.Charts = buildCharts(pkg.Name, r.Rankings)
+		if r.Profiles, err = loadProfiles(opts.profileDir, opts.profiles, pkg.Name, opts.profileTop); err != nil {
+			return err
+		}
+		runs = append(runs, r)
+	}
+
+	set := newResultSet(env, start, runs)
+	set.Commit = gitCommit(ctx, opts.root)
+	set.Machine = machineName()
+	set.Hardware = detectHardware(env)
+	if set.Hardware.CPUModel == "" {
+		set.Hardware.CPUModel = set.CPU
+	}
+	if opts.archive != "" {
+		path, err := archiveResultSet(opts.archive, set)
+		if err != nil {
+			return err
+		}
+		log.Printf("results archived in %s", path)
+	}
+	if opts.jsonOut != "" {
+		if err := writeFile(opts.jsonOut, func(w io.Writer) error { return writeJSON(w, set) }); err != nil {
+			return err
+		}
+	}
+	if opts.csvOut != "" {
+		if err := writeFile(opts.csvOut, func(w io.Writer) error { return writeCSV(w, set) }); err != nil {
+			return err
+		}
+	}
+	if opts.summaryCSV != "" {
+		if err := writeFile(opts.summaryCSV, func(w io.Writer) error { return writeSummaryCSV(w, set) }); err != nil {
+			return err
+		}
+	}
+
+	if opts.report != "" {
+		history := []*ResultSet{set}
+		if opts.archive != "" {
+			history, err = loadArchive(opts.archive)
+			if err != nil {
+				return err
+			}
+		}
+		if err := writeReport(opts.report, set, goTestCommand(opts), runs, history); err != nil {
+			return err
+		}
+		log.Printf("report written to %s", opts.report)
+	}
+
+	data := readmeData{
+		Env:      env,
+		Hardware: set.Hardware,
+		Command:  goTestCommand(opts),
+		MaxCV:    opts.maxCV,
+		Packages: runs,
+	}
+	if opts.charts != "" {
+		if err := writeCharts(opts.charts, runs); err != nil {
+			return err
+		}
+		data.ChartDir, err = chartLink(opts.readme, opts.charts)
+		if err != nil {
+			return err
+		}
+	}
+	return writeReadme(opts.readme, opts.template, data)
+}
+
+// filterPackages keeps only the packages named in the comma separated list.
+// An unknown name is an error, so typos do not silently shrink the README.
+func filterPackages(pkgs []*benchPackage, list string) ([]*benchPackage, error) {
+	if list == "" {
+		return pkgs, nil
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.Trim(strings.TrimSpace(name), "./")
+		if name != "" {
+			wanted[name] = true
+		}
+	}
+
+	var filtered []*benchPackage
+	for _, pkg := range pkgs {
+		if wanted[pkg.Name] {
+			filtered = append(filtered, pkg)
+			delete(wanted, pkg.Name)
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("package %q has no benchmarks or does not exist", name)
+	}
+	return filtered, nil
+}
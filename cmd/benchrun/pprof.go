@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The pprof files go test writes are gzipped profile.proto messages, see
+// https://github.com/google/pprof/blob/main/proto/profile.proto.
+// Only the fields needed for hot function tables and flamegraphs are
+// decoded, which keeps the runner free of dependencies.
+
+// profile is the decoded subset of a pprof profile.
+type profile struct {
+	SampleTypes []valueType
+	DefaultType string // default_sample_type, empty if unset
+	Samples     []profileSample
+}
+
+type valueType struct {
+	Type, Unit string
+}
+
+// profileSample is a stack with one value per sample type.
+type profileSample struct {
+	Stack  []string // function names, leaf first
+	Values []int64
+}
+
+// protobuf wire types
+const (
+	wireVarint = 0
+	wireI64    = 1
+	wireLen    = 2
+	wireI32    = 5
+)
+
+// protoField is a single field of an encoded message.
+type protoField struct {
+	num   int
+	wire  int
+	value uint64 // varint and fixed size values
+	data  []byte // length delimited values
+}
+
+// protoFields splits an encoded message into its fields.
+func protoFields(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("pprof: bad field key")
+		}
+		b = b[n:]
+		f := protoField{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case wireVarint:
+			f.value, n = binary.Uvarint(b)
+			if n <= 0 {
+				return nil, errors.New("pprof: bad varint")
+			}
+			b = b[n:]
+		case wireI64:
+			if len(b) < 8 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			f.value, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireI32:
+			if len(b) < 4 {
+				return nil, io.ErrUnexpectedEOF
+			}
+			f.value, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case wireLen:
+			l, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return nil, errors.New("pprof: bad length")
+			}
+			f.data, b = b[n:n+int(l)], b[n+int(l):]
+		default:
+			return nil, fmt.Errorf("pprof: unsupported wire type %d", f.wire)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// varints returns the values of a repeated integer field, which may be
+// packed into a single length delimited field or repeated.
+func (f protoField) varints() ([]uint64, error) {
+	if f.wire == wireVarint {
+		return []uint64{f.value}, nil
+	}
+	var vs []uint64
+	for b := f.data; len(b) > 0; {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, errors.New("pprof: bad packed varint")
+		}
+		vs, b = append(vs, v), b[n:]
+	}
+	return vs, nil
+}
+
+// parseProfile decodes a pprof profile, gzipped or not.
+func parseProfile(r io.Reader) (*profile, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+	} else {
+		r = br
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := protoFields(b)
+	if err != nil {
+		return nil, err
+	}
+
+	type rawSample struct {
+		locations []uint64
+		values    []uint64
+	}
+	var (
+		strs        []string
+		sampleTypes [][2]uint64
+		rawSamples  []rawSample
+		defaultType uint64
+		locations   = make(map[uint64][]uint64) // location id -> function ids, innermost first
+		functions   = make(map[uint64]uint64)   // function id -> name string index
+	)
+	for _, f := range fields {
+		switch f.num {
+		case 1: // sample_type
+			vt, err := protoFields(f.data)
+			if err != nil {
+				return nil, err
+			}
+			var t [2]uint64
+			for _, v := range vt {
+				if v.num == 1 || v.num == 2 {
+					t[v.num-1] = v.value
+				}
+			}
+			sampleTypes = append(sampleTypes, t)
+		case 2: // sample
+			sf, err := protoFields(f.data)
+			if err != nil {
+				return nil, err
+			}
+			var s rawSample
+			for _, v := range sf {
+				vs, err := v.varints()
+				if err != nil {
+					return nil, err
+				}
+				switch v.num {
+				case 1:
+					s.locations = append(s.locations, vs...)
+				case 2:
+					s.values = append(s.values, vs...)
+				}
+			}
+			rawSamples = append(rawSamples, s)
+		case 4: // location
+			lf, err := protoFields(f.data)
+			if err != nil {
+				return nil, err
+			}
+			var id uint64
+			var funcs []uint64
+			for _, v := range lf {
+				switch v.num {
+				case 1:
+					id = v.value
+				case 4: // line
+					line, err := protoFields(v.data)
+					if err != nil {
+						return nil, err
+					}
+					for _, l := range line {
+						if l.num == 1 {
+							funcs = append(funcs, l.value)
+						}
+					}
+				}
+			}
+			locations[id] = funcs
+		case 5: // function
+			ff, err := protoFields(f.data)
+			if err != nil {
+				return nil, err
+			}
+			var id, name uint64
+			for _, v := range ff {
+				switch v.num {
+				case 1:
+					id = v.value
+				case 2:
+					name = v.value
+				}
+			}
+			functions[id] = name
+		case 6: // string_table
+			strs = append(strs, string(f.data))
+		case 14: // default_sample_type
+			defaultType = f.value
+		}
+	}
+
+	str := func(i uint64) string {
+		if i < uint64(len(strs)) {
+			return strs[i]
+		}
+		return ""
+	}
+	p := &profile{DefaultType: str(defaultType)}
+	for _, t := range sampleTypes {
+		p.SampleTypes = append(p.SampleTypes, valueType{Type: str(t[0]), Unit: str(t[1])})
+	}
+	for _, rs := range rawSamples {
+		s := profileSample{Values: make([]int64, len(rs.values))}
+		for i, v := range rs.values {
+			s.Values[i] = int64(v)
+		}
+		for _, loc := range rs.locations {
+			funcs, ok := locations[loc]
+			if !ok || len(funcs) == 0 {
+				s.Stack = append(s.Stack, fmt.Sprintf("0x%x", loc))
+				continue
+			}
+			for _, fn := range funcs {
+				s.Stack = append(s.Stack, str(functions[fn]))
+			}
+		}
+		p.Samples = append(p.Samples, s)
+	}
+	return p, nil
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// profileKinds maps the names accepted by -profile to the go test flag
+// that writes the profile.
+var profileKinds = []struct{ name, flag string }{
+	{"cpu", "-cpuprofile"},
+	{"mem", "-memprofile"},
+	{"mutex", "-mutexprofile"},
+	{"block", "-blockprofile"},
+}
+
+// parseProfileKinds validates the comma separated -profile list and
+// returns it in the order of profileKinds.
+func parseProfileKinds(list string) ([]string, error) {
+	if list == "" {
+		return nil, nil
+	}
+	wanted := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		wanted[strings.TrimSpace(name)] = true
+	}
+	var kinds []string
+	for _, k := range profileKinds {
+		if wanted[k.name] {
+			kinds = append(kinds, k.name)
+			delete(wanted, k.name)
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("unknown profile %q, want cpu, mem, mutex or block", name)
+	}
+	return kinds, nil
+}
+
+// profileArgs returns the go test flags that write the requested profiles
+// of pkg into dir. The test binary is kept next to the profiles, so they
+// can be inspected with go tool pprof later.
+func profileArgs(dir string, kinds []string, pkg string) []string {
+	if len(kinds) == 0 {
+		return nil
+	}
+	args := []string{"-o", filepath.Join(dir, fileSafe(pkg)+".test")}
+	for _, kind := range kinds {
+		for _, k := range profileKinds {
+			if k.name == kind {
+				args = append(args, k.flag, profilePath(dir, pkg, kind))
+			}
+		}
+	}
+	return args
+}
+
+func profilePath(dir, pkg, kind string) string {
+	return filepath.Join(dir, fileSafe(pkg)+"-"+kind+".pprof")
+}
+
+// profileSummary is the part of a profile shown in the report.
+type profileSummary struct {
+	Kind       string // "cpu", "mem", "mutex" or "block"
+	Type, Unit string // sample type the summary is about, e.g. "alloc_space", "bytes"
+	Total      int64
+	Top        []hotFunc
+	Flamegraph []byte // SVG
+}
+
+// hotFunc is a function of a profile with its own (flat) and
+// inclusive (cumulative) share of the total.
+type hotFunc struct {
+	Name      string
+	Flat, Cum int64
+}
+
+// share returns v relative to total.
+func share(v, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(v) / float64(total)
+}
+
+// summaryType picks the sample type that answers "where does the time or
+// memory go": allocated bytes for heap profiles, which are more telling
+// for benchmarks than the bytes in use at exit, otherwise the last type,
+// which is the time for CPU, mutex and block profiles.
+func (p *profile) summaryType() int {
+	for i, t := range p.SampleTypes {
+		if t.Type == "alloc_space" {
+			return i
+		}
+	}
+	return len(p.SampleTypes) - 1
+}
+
+// summarizeProfile computes the top n functions by flat value of the
+// sample type vi.
+func summarizeProfile(p *profile, vi, n int) (total int64, top []hotFunc) {
+	flat := make(map[string]int64)
+	cum := make(map[string]int64)
+	for _, s := range p.Samples {
+		if vi >= len(s.Values) || len(s.Stack) == 0 {
+			continue
+		}
+		v := s.Values[vi]
+		total += v
+		flat[s.Stack[0]] += v
+		seen := make(map[string]bool, len(s.Stack))
+		for _, fn := range s.Stack {
+			if !seen[fn] { // recursion counts once
+				seen[fn] = true
+				cum[fn] += v
+			}
+		}
+	}
+
+	for name, c := range cum {
+		top = append(top, hotFunc{Name: name, Flat: flat[name], Cum: c})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Flat != top[j].Flat {
+			return top[i].Flat > top[j].Flat
+		}
+		if top[i].Cum != top[j].Cum {
+			return top[i].Cum > top[j].Cum
+		}
+		return top[i].Name < top[j].Name
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return total, top
+}
+
+// loadProfiles reads the profiles written for pkg, summarizes them and
+// writes a flamegraph of each next to it. Profiles without samples, such
+// as a mutex profile of a package without locks, are skipped.
+func loadProfiles(dir string, kinds []string, pkg string, n int) ([]*profileSummary, error) {
+	var sums []*profileSummary
+	for _, kind := range kinds {
+		path := profilePath(dir, pkg, kind)
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		p, err := parseProfile(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if len(p.SampleTypes) == 0 {
+			continue
+		}
+
+		vi := p.summaryType()
+		s := &profileSummary{Kind: kind, Type: p.SampleTypes[vi].Type, Unit: p.SampleTypes[vi].Unit}
+		s.Total, s.Top = summarizeProfile(p, vi, n)
+		if s.Total == 0 {
+			continue
+		}
+		s.Flamegraph = renderFlamegraph(p, vi, fmt.Sprintf("%s %s profile (%s)", pkg, kind, s.Type))
+		svg := strings.TrimSuffix(path, ".pprof") + ".svg"
+		if err := os.WriteFile(svg, s.Flamegraph, 0o644); err != nil {
+			return nil, err
+		}
+		sums = append(sums, s)
+	}
+	return sums, nil
+}
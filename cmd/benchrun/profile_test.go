@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"testing"
+)
+
+func TestParseProfileKinds(t *testing.T) {
+	kinds, err := parseProfileKinds("block, cpu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cpu", "block"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("kinds = %v, want %v", kinds, want)
+	}
+	if _, err := parseProfileKinds("cpu,heap"); err == nil {
+		t.Error("unknown profile accepted")
+	}
+}
+
+var profileSink [][]byte
+
+func TestParseProfile(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		profileSink = append(profileSink, make([]byte, 64<<10))
+	}
+	runtime.GC()
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("allocs").WriteTo(&buf, 0); err != nil {
+		t.Fatal(err)
+	}
+	p, err := parseProfile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vi := p.summaryType()
+	if got := p.SampleTypes[vi]; got != (valueType{"alloc_space", "bytes"}) {
+		t.Fatalf("summary type = %v, want alloc_space in bytes", got)
+	}
+	total, top := summarizeProfile(p, vi, 1000)
+	if total < 100*64<<10/2 {
+		t.Errorf("total = %d, want about %d", total, 100*64<<10)
+	}
+	found := false
+	for _, f := range top {
+		found = found || strings.HasSuffix(f.Name, ".TestParseProfile")
+	}
+	if !found {
+		t.Errorf("TestParseProfile is not among the allocating functions: %+v", top)
+	}
+}
+
+func TestSummarizeProfile(t *testing.T) {
+	p := &profile{
+		SampleTypes: []valueType{{"samples", "count"}, {"cpu", "nanoseconds"}},
+		Samples: []profileSample{
+			{Stack: []string{"leaf", "mid", "main"}, Values: []int64{3, 30}},
+			{Stack: []string{"mid", "main"}, Values: []int64{1, 10}},
+			{Stack: []string{"rec", "rec", "main"}, Values: []int64{6, 60}},
+		},
+	}
+	total, top := summarizeProfile(p, p.summaryType(), 3)
+	if total != 100 {
+		t.Errorf("total = %d, want 100", total)
+	}
+	want := []hotFunc{{"rec", 60, 60}, {"leaf", 30, 30}, {"mid", 10, 40}}
+	if !reflect.DeepEqual(top, want) {
+		t.Errorf("top = %+v, want %+v", top, want)
+	}
+
+	svg := renderFlamegraph(p, 1, "test <profile>")
+	dec := xml.NewDecoder(bytes.NewReader(svg))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("flamegraph is not well-formed XML: %v\n%s", err, svg)
+		}
+	}
+	for _, want := range []string{"all: 100ns (100.00%)", "rec: 60ns (60.00%)", "test &lt;profile&gt;"} {
+		if !bytes.Contains(svg, []byte(want)) {
+			t.Errorf("flamegraph lacks %q", want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Ranking orders benchmarks that answer the same question, the variants,
+// from fastest to slowest.
+//
+// Sub-benchmarks are variants of their parent, e.g. the SQLite and TinySQL
+// runs of Benchmark_SQLCompare/SelectJoin. Top level benchmarks without
+// sub-benchmarks are variants of each other within their package, e.g.
+// BenchmarkConcatString and BenchmarkConcatBuilder.
+type Ranking struct {
+	Group   string // parent benchmark path, empty for the top level benchmarks of a package
+	Entries []RankEntry
+}
+
+// RankEntry is one variant of a Ranking.
+type RankEntry struct {
+	Variant  string // name relative to the group
+	Summary  Summary
+	Slowdown float64 // median ns/op relative to the fastest variant, 1 for the fastest
+}
+
+// rankSummaries builds a ranking for every group of at least two variants,
+// in order of the first appearance of each group.
+func rankSummaries(sums []Summary) []Ranking {
+	// A benchmark run with several -cpu values has a variant per value.
+	procs := make(map[string]map[int]bool)
+	for _, s := range sums {
+		if procs[s.Name] == nil {
+			procs[s.Name] = make(map[int]bool)
+		}
+		procs[s.Name][s.Procs] = true
+	}
+
+	var order []string
+	groups := make(map[string][]RankEntry)
+	for _, s := range sums {
+		group, variant := "", s.Name
+		if i := strings.LastIndexByte(s.Name, '/'); i >= 0 {
+			group, variant = s.Name[:i], s.Name[i+1:]
+		}
+		if len(procs[s.Name]) > 1 {
+			variant = fmt.Sprintf("%s-%d", variant, s.Procs)
+		}
+		if _, ok := groups[group]; !ok {
+			order = append(order, group)
+		}
+		groups[group] = append(groups[group], RankEntry{Variant: variant, Summary: s})
+	}
+
+	var rankings []Ranking
+	for _, group := range order {
+		entries := groups[group]
+		if len(entries) < 2 {
+			continue
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].Summary.NsPerOp.Median < entries[j].Summary.NsPerOp.Median
+		})
+		fastest := entries[0].Summary.NsPerOp.Median
+		for i := range entries {
+			if fastest > 0 {
+				entries[i].Slowdown = entries[i].Summary.NsPerOp.Median / fastest
+			}
+		}
+		rankings = append(rankings, Ranking{Group: group, Entries: entries})
+	}
+	return rankings
+}
@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+func summary(name string, procs int, ns float64) Summary {
+	return Summary{Name: name, Procs: procs, NsPerOp: Stats{Median: ns}}
+}
+
+func TestRankSummaries(t *testing.T) {
+	sums := []Summary{
+		summary("BenchmarkConcatString", 12, 4000),
+		summary("BenchmarkConcatBuilder", 12, 200),
+		summary("Benchmark_SQLCompare/SelectJoin/SQLite", 12, 300),
+		summary("Benchmark_SQLCompare/SelectJoin/TinySQL", 12, 900),
+		summary("Benchmark_SQLCompare/SelectPoint/SQLite", 12, 50),
+		summary("BenchmarkScale", 1, 100),
+		summary("BenchmarkScale", 4, 50),
+	}
+
+	rankings := rankSummaries(sums)
+	if len(rankings) != 2 {
+		t.Fatalf("got %d rankings, want 2: %+v", len(rankings), rankings)
+	}
+
+	top := rankings[0]
+	if top.Group != "" {
+		t.Errorf("first group = %q, want the top level benchmarks", top.Group)
+	}
+	wantTop := []struct {
+		variant  string
+		slowdown float64
+	}{
+		{"BenchmarkScale-4", 1},
+		{"BenchmarkScale-1", 2},
+		{"BenchmarkConcatBuilder", 4},
+		{"BenchmarkConcatString", 80},
+	}
+	if len(top.Entries) != len(wantTop) {
+		t.Fatalf("top level entries = %+v", top.Entries)
+	}
+	for i, want := range wantTop {
+		if e := top.Entries[i]; e.Variant != want.variant || e.Slowdown != want.slowdown {
+			t.Errorf("entry %d = %s %.2fx, want %s %.2fx", i, e.Variant, e.Slowdown, want.variant, want.slowdown)
+		}
+	}
+
+	join := rankings[1]
+	if join.Group != "Benchmark_SQLCompare/SelectJoin" || len(join.Entries) != 2 {
+		t.Fatalf("second ranking = %+v", join)
+	}
+	if join.Entries[0].Variant != "SQLite" || join.Entries[1].Variant != "TinySQL" || join.Entries[1].Slowdown != 3 {
+		t.Errorf("join ranking = %+v", join.Entries)
+	}
+}
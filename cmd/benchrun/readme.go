@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed README.md.tmpl
+var defaultReadmeTemplate string
+
+// readmeData is the data README.md.tmpl is executed with.
+type readmeData struct {
+	Env      goEnv
+	Hardware Hardware
+	Command  string  // go test command line shown above the raw output
+	MaxCV    float64 // noise limit, see Summary.Noisy
+	ChartDir string  // chart directory relative to the README, empty without charts
+	Packages []*packageRun
+}
+
+// chartLink returns the chart directory as a slash separated path
+// relative to the directory of the README.
+func chartLink(readme, charts string) (string, error) {
+	readmeDir, err := filepath.Abs(filepath.Dir(readme))
+	if err != nil {
+		return "", err
+	}
+	chartDir, err := filepath.Abs(charts)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(readmeDir, chartDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+var readmeFuncs = template.FuncMap{
+	"inc":          func(i int) int { return i + 1 },
+	"metric":       formatMetric,
+	"custom":       customMetric,
+	"scalingLimit": func() float64 { return scalingLimit },
+	"percent": func(v float64) string {
+		return fmt.Sprintf("%.1f%%", v*100)
+	},
+	"slowdown": func(v float64) string {
+		if v == 1 {
+			return "fastest"
+		}
+		return fmt.Sprintf("%.2fx", v)
+	},
+}
+
+// ReleaseNotes links the release notes of the Go version the benchmarks ran on.
+func (e goEnv) ReleaseNotes() string {
+	v := strings.TrimPrefix(e.GoVersion, "go")
+	if major, rest, ok := strings.Cut(v, "."); ok {
+		minor, _, _ := strings.Cut(rest, ".")
+		minor, _, _ = strings.Cut(minor, "rc")
+		v = major + "." + minor
+	}
+	return "https://tip.golang.org/doc/go" + v
+}
+
+// HasThroughput reports whether any benchmark of the package reports MB/s.
+func (r *packageRun) HasThroughput() bool {
+	for _, s := range r.Summaries {
+		if s.MBPerSec != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// MetricUnits returns the sorted custom units reported by the benchmarks
+// of the package, one extra column each.
+func (r *packageRun) MetricUnits() []string {
+	seen := make(map[string]bool)
+	var units []string
+	for _, s := range r.Summaries {
+		for unit := range s.Metrics {
+			if !seen[unit] {
+				seen[unit] = true
+				units = append(units, unit)
+			}
+		}
+	}
+	sort.Strings(units)
+	return units
+}
+
+// customMetric formats the median of a custom unit, empty if the
+// benchmark does not report it.
+func customMetric(s Summary, unit string) string {
+	v, ok := s.Metrics[unit]
+	if !ok {
+		return ""
+	}
+	return formatMetric(v)
+}
+
+// Source returns the concatenated test files of the package.
+func (r *packageRun) Source() (string, error) {
+	var buf strings.Builder
+	for i, file := range r.Files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.Write(bytes.TrimRight(src, "\n"))
+		buf.WriteByte('\n')
+	}
+	return buf.String(), nil
+}
+
+func writeReadme(path, tmplPath string, data readmeData) error {
+	text := defaultReadmeTemplate
+	if tmplPath != "" {
+		b, err := os.ReadFile(tmplPath)
+		if err != nil {
+			return err
+		}
+		text = string(b)
+	}
+
+	tmpl, err := template.New("README.md").Funcs(readmeFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("render %s: %w", path, err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+func TestReleaseNotes(t *testing.T) {
+	for version, want := range map[string]string{
+		"go1.26.4":  "https://tip.golang.org/doc/go1.26",
+		"go1.27":    "https://tip.golang.org/doc/go1.27",
+		"go1.27rc1": "https://tip.golang.org/doc/go1.27",
+	} {
+		if got := (goEnv{GoVersion: version}).ReleaseNotes(); got != want {
+			t.Errorf("ReleaseNotes(%s) = %q, want %q", version, got, want)
+		}
+	}
+}
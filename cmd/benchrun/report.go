@@ -0,0 +1,170 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+// historyRuns is the number of archived runs shown on the report pages.
+const historyRuns = 12
+
+// reportData is the data of the report index page.
+type reportData struct {
+	Set      *ResultSet // the run the report was generated for
+	Command  string
+	Packages []*reportPackage
+	History  []*ResultSet // archived runs, oldest first
+}
+
+// reportPackage is the data of a report package page.
+type reportPackage struct {
+	*packageRun
+	Set     *ResultSet
+	Command string
+	SVGs    []template.HTML // inline charts
+
+	Flamegraphs []template.HTML // inline flamegraph per profile
+	History     historyTable
+}
+
+// historyTable has a row per benchmark of the package and a column per run.
+type historyTable struct {
+	Runs []*ResultSet
+	Rows []historyRow
+}
+
+type historyRow struct {
+	Name   string
+	Values []float64 // median ns/op per run, NaN if the run lacks the benchmark
+}
+
+var reportFuncs = template.FuncMap{
+	"metric": func(v float64) string {
+		if math.IsNaN(v) {
+			return "–"
+		}
+		return formatMetric(v)
+	},
+	"sortkey": func(v float64) float64 {
+		if math.IsNaN(v) {
+			return math.MaxFloat64
+		}
+		return v
+	},
+	"percent":      readmeFuncs["percent"],
+	"slowdown":     readmeFuncs["slowdown"],
+	"inc":          readmeFuncs["inc"],
+	"custom":       customMetric,
+	"scalingLimit": readmeFuncs["scalingLimit"],
+	"benchLabel":   benchLabel,
+	"reportPage":   reportPage,
+	"profileValue": formatProfileValue,
+	"share":        share,
+}
+
+// benchLabel names a summary the way go test prints it.
+func benchLabel(s Summary) string {
+	if s.Procs > 1 {
+		return fmt.Sprintf("%s-%d", s.Name, s.Procs)
+	}
+	return s.Name
+}
+
+// writeReport renders the static HTML report into dir: an index page and
+// one page per package with sortable tables, the benchmark sources, the
+// charts and the results of the archived runs.
+func writeReport(dir string, set *ResultSet, command string, runs []*packageRun, history []*ResultSet) error {
+	tmpl, err := template.New("report").Funcs(reportFuncs).Parse(reportTemplate)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if len(history) > historyRuns {
+		history = history[len(history)-historyRuns:]
+	}
+
+	data := reportData{Set: set, Command: command, History: history}
+	for _, r := range runs {
+		p := &reportPackage{
+			packageRun: r,
+			Set:        set,
+			Command:    command,
+			History:    buildHistory(r.Name, history),
+		}
+		for _, c := range r.Charts {
+			svg, err := renderChart(c)
+			if err != nil {
+				return fmt.Errorf("chart %s: %w", c.File, err)
+			}
+			p.SVGs = append(p.SVGs, template.HTML(svg))
+		}
+		for _, prof := range r.Profiles {
+			p.Flamegraphs = append(p.Flamegraphs, template.HTML(prof.Flamegraph))
+		}
+		data.Packages = append(data.Packages, p)
+
+		if err := writeTemplate(tmpl, "package", filepath.Join(dir, reportPage(r.Name)), p); err != nil {
+			return err
+		}
+	}
+	return writeTemplate(tmpl, "index", filepath.Join(dir, "index.html"), data)
+}
+
+func writeTemplate(tmpl *template.Template, name, path string, data any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.ExecuteTemplate(f, name, data); err != nil {
+		f.Close()
+		return fmt.Errorf("render %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// reportPage returns the file name of the page of a package,
+// nested packages such as "a/b" become "a-b.html".
+func reportPage(pkg string) string {
+	return fileSafe(pkg) + ".html"
+}
+
+// buildHistory collects the median ns/op of every benchmark of pkg
+// in each run. Runs archived before summaries were recorded are
+// summarized from their raw results.
+func buildHistory(pkg string, runs []*ResultSet) historyTable {
+	t := historyTable{Runs: runs}
+	index := make(map[string]int)
+	for i, run := range runs {
+		sums := run.Summaries
+		if len(sums) == 0 {
+			sums = summarize(run.Results, math.Inf(1))
+		}
+		for _, s := range sums {
+			if s.Package != pkg {
+				continue
+			}
+			name := benchLabel(s)
+			row, ok := index[name]
+			if !ok {
+				row = len(t.Rows)
+				index[name] = row
+				values := make([]float64, len(runs))
+				for j := range values {
+					values[j] = math.NaN()
+				}
+				t.Rows = append(t.Rows, historyRow{Name: name, Values: values})
+			}
+			t.Rows[row].Values[i] = s.NsPerOp.Median
+		}
+	}
+	return t
+}
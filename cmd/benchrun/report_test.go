@@ -0,0 +1,97 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildHistory(t *testing.T) {
+	old := &ResultSet{Results: []Result{
+		{Package: "hash", Name: "BenchmarkMD5", Procs: 1, NsPerOp: 100},
+		{Package: "hash", Name: "BenchmarkMD5", Procs: 1, NsPerOp: 120},
+		{Package: "split", Name: "BenchmarkSplit", Procs: 1, NsPerOp: 5},
+	}}
+	cur := &ResultSet{Summaries: []Summary{
+		{Package: "hash", Name: "BenchmarkSHA256", Procs: 1, NsPerOp: Stats{Median: 300}},
+		{Package: "hash", Name: "BenchmarkMD5", Procs: 4, NsPerOp: Stats{Median: 90}},
+	}}
+
+	h := buildHistory("hash", []*ResultSet{old, cur})
+	if len(h.Rows) != 3 {
+		t.Fatalf("got %d rows, want 3: %+v", len(h.Rows), h.Rows)
+	}
+	want := []struct {
+		name   string
+		values [2]float64
+	}{
+		{"BenchmarkMD5", [2]float64{110, math.NaN()}},
+		{"BenchmarkSHA256", [2]float64{math.NaN(), 300}},
+		{"BenchmarkMD5-4", [2]float64{math.NaN(), 90}},
+	}
+	for i, w := range want {
+		row := h.Rows[i]
+		if row.Name != w.name {
+			t.Errorf("row %d = %s, want %s", i, row.Name, w.name)
+		}
+		for j, v := range w.values {
+			if got := row.Values[j]; got != v && !(math.IsNaN(got) && math.IsNaN(v)) {
+				t.Errorf("%s run %d = %v, want %v", row.Name, j, got, v)
+			}
+		}
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	dir := t.TempDir()
+	sums := []Summary{
+		{Package: "nested/pkg", Name: "BenchmarkA", Procs: 1, NsPerOp: Stats{Samples: 1, Median: 10}},
+		{Package: "nested/pkg", Name: "BenchmarkB", Procs: 1, NsPerOp: Stats{Samples: 1, Median: 30}},
+	}
+	run := &packageRun{
+		benchPackage: &benchPackage{
+			Name:       "nested/pkg",
+			Benchmarks: []string{"BenchmarkA", "BenchmarkB"},
+			Sources: map[string]string{
+				"BenchmarkA": "func BenchmarkA(b *testing.B) { _ = a < b }",
+				"BenchmarkB": "func BenchmarkB(b *testing.B) {}",
+			},
+		},
+		Output:    "BenchmarkA \t1\t10 ns/op\n",
+		Summaries: sums,
+	}
+	run.Rankings = rankSummaries(sums)
+	run.Charts = buildCharts(run.Name, run.Rankings)
+	set := &ResultSet{Date: time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC), GoVersion: "go1.26.0", Commit: "abc1234", Summaries: sums}
+
+	if err := writeReport(dir, set, "go test -bench .", []*packageRun{run}, []*ResultSet{set}); err != nil {
+		t.Fatal(err)
+	}
+
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), `href="nested-pkg.html"`) {
+		t.Errorf("index does not link the package page:\n%s", index)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "nested-pkg.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<svg",         // inline chart
+		"_ = a &lt; b", // escaped source
+		`<table class="sortable">`,
+		"<h2>History</h2>",
+		"3.00x",
+	} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("package page lacks %q", want)
+		}
+	}
+}
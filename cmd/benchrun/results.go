@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResultSet is everything a single benchrun invocation measured.
+// It is the document written by -json and read back by later tooling.
+type ResultSet struct {
+	Date      time.Time `json:"date"`
+	Commit    string    `json:"commit"`  // short git hash, "-dirty" if the tree had local changes
+	Machine   string    `json:"machine"` // host name
+	GoVersion string    `json:"go_version"`
+	GOOS      string    `json:"goos"`
+	GOARCH    string    `json:"goarch"`
+	CPU       string    `json:"cpu"`
+	Hardware  Hardware  `json:"hardware"`
+	Results   []Result  `json:"results"`
+	Summaries []Summary `json:"summaries"`
+}
+
+// Result is a single benchmark result line of go test output.
+type Result struct {
+	Package     string             `json:"package"`       // package directory, e.g. "sql"
+	Name        string             `json:"name"`          // full name without the -procs suffix, e.g. "Benchmark_SQLCompare/SelectJoin/TinySQL"
+	Benchmark   string             `json:"benchmark"`     // top level function, e.g. "Benchmark_SQLCompare"
+	Sub         string             `json:"sub,omitempty"` // sub-benchmark path, e.g. "SelectJoin/TinySQL"
+	Procs       int                `json:"procs"`
+	Iterations  int64              `json:"iterations"`
+	NsPerOp     float64            `json:"ns_per_op"`
+	BytesPerOp  float64            `json:"bytes_per_op"`
+	AllocsPerOp float64            `json:"allocs_per_op"`
+	MBPerSec    float64            `json:"mb_per_s,omitempty"`
+	Metrics     map[string]float64 `json:"metrics,omitempty"` // custom units reported via b.ReportMetric
+}
+
+// newResultSet flattens the results of all package runs.
+func newResultSet(env goEnv, date time.Time, runs []*packageRun) *ResultSet {
+	set := &ResultSet{
+		Date:      date.UTC(),
+		GoVersion: env.GoVersion,
+		GOOS:      env.GOOS,
+		GOARCH:    env.GOARCH,
+	}
+	for _, r := range runs {
+		if set.CPU == "" {
+			set.CPU = r.CPU
+		}
+		set.Results = append(set.Results, r.Results...)
+		set.Summaries = append(set.Summaries, r.Summaries...)
+	}
+	return set
+}
+
+// parseBenchLine parses a line such as
+//
+//	Benchmark_SQLCompare/SelectJoin/TinySQL-12   	    1234	    956789 ns/op	  1024 B/op	  12 allocs/op
+//
+// names holds the benchmark names go test reported via run events; it is
+// used to tell a -procs suffix apart from a sub-benchmark that ends in -N.
+func parseBenchLine(line string, names map[string]bool) (Result, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 || !strings.HasPrefix(fields[0], "Benchmark") {
+		return Result{}, false
+	}
+
+	iterations, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return Result{}, false
+	}
+
+	r := Result{Name: fields[0], Procs: 1, Iterations: iterations}
+	if i := strings.LastIndexByte(r.Name, '-'); i > 0 && !names[r.Name] {
+		if procs, err := strconv.Atoi(r.Name[i+1:]); err == nil && procs > 0 {
+			r.Name, r.Procs = r.Name[:i], procs
+		}
+	}
+	r.Benchmark, r.Sub, _ = strings.Cut(r.Name, "/")
+
+	for i := 2; i < len(fields); i += 2 {
+		value, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Result{}, false
+		}
+		switch unit := fields[i+1]; unit {
+		case "ns/op":
+			r.NsPerOp = value
+		case "B/op":
+			r.BytesPerOp = value
+		case "allocs/op":
+			r.AllocsPerOp = value
+		case "MB/s":
+			r.MBPerSec = value
+		default:
+			if r.Metrics == nil {
+				r.Metrics = make(map[string]float64)
+			}
+			r.Metrics[unit] = value
+		}
+	}
+	return r, true
+}
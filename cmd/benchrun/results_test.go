@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBenchLine(t *testing.T) {
+	names := map[string]bool{
+		"Benchmark_SQLCompare/SelectJoin/TinySQL": true,
+		"BenchmarkSizes/size-1024":                true,
+	}
+
+	tests := []struct {
+		line string
+		want Result
+		ok   bool
+	}{
+		{
+			line: "Benchmark_SQLCompare/SelectJoin/TinySQL-12   \t    1234\t    956789 ns/op\t  1024 B/op\t  12 allocs/op",
+			want: Result{
+				Name: "Benchmark_SQLCompare/SelectJoin/TinySQL", Benchmark: "Benchmark_SQLCompare", Sub: "SelectJoin/TinySQL",
+				Procs: 12, Iterations: 1234, NsPerOp: 956789, BytesPerOp: 1024, AllocsPerOp: 12,
+			},
+			ok: true,
+		},
+		{
+			line: "BenchmarkSizes/size-1024 \t 100\t 5.5 ns/op\t 186.2 MB/s\t 3.000 hits/op",
+			want: Result{
+				Name: "BenchmarkSizes/size-1024", Benchmark: "BenchmarkSizes", Sub: "size-1024",
+				Procs: 1, Iterations: 100, NsPerOp: 5.5, MBPerSec: 186.2, Metrics: map[string]float64{"hits/op": 3},
+			},
+			ok: true,
+		},
+		{
+			line: "BenchmarkConcatBuilder-8 \t 7000000\t 170.3 ns/op",
+			want: Result{Name: "BenchmarkConcatBuilder", Benchmark: "BenchmarkConcatBuilder", Procs: 8, Iterations: 7000000, NsPerOp: 170.3},
+			ok:   true,
+		},
+		{line: "BenchmarkSplitMethods", ok: false},
+		{line: "--- BENCH: BenchmarkNumberRegEx-12", ok: false},
+		{line: "BenchmarkX-4 \t many \t 1 ns/op", ok: false},
+		{line: "ok  \tgithub.com/SimonWaldherr/golang-benchmarks/split\t0.007s", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseBenchLine(tt.line, names)
+		if ok != tt.ok {
+			t.Errorf("parseBenchLine(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseBenchLine(%q) =\n%+v\nwant\n%+v", tt.line, got, tt.want)
+		}
+	}
+}
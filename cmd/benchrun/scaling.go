@@ -0,0 +1,94 @@
+package main
+
+import "sort"
+
+// scalingLimit is the parallel efficiency below which a benchmark counts
+// as no longer scaling: with the most procs every core delivers less than
+// this share of what a single core does.
+const scalingLimit = 0.6
+
+// Scaling describes how the throughput of a benchmark run with several
+// -cpu values grows with GOMAXPROCS.
+type Scaling struct {
+	Name    string
+	Unit    string // MB/s if the benchmark reports it, op/s otherwise
+	Points  []ScalingPoint
+	Flagged bool // efficiency at the most procs below scalingLimit
+}
+
+// ScalingPoint is the throughput of one -cpu value.
+type ScalingPoint struct {
+	Procs      int
+	Throughput float64 // of all procs together
+	PerCore    float64 // Throughput / Procs
+	Efficiency float64 // PerCore relative to the run with the fewest procs
+}
+
+// Last returns the point with the most procs.
+func (s Scaling) Last() ScalingPoint {
+	return s.Points[len(s.Points)-1]
+}
+
+// scalingOf computes the scaling of every benchmark that ran at more
+// than one GOMAXPROCS, in order of first appearance. Only the benchmarks
+// of parallel are considered: a sequential one runs on one core at every
+// -cpu value, its efficiency would drop with the procs by design.
+func scalingOf(sums []Summary, parallel map[string]bool) []Scaling {
+	var order []string
+	byName := make(map[string][]Summary)
+	for _, s := range sums {
+		if !parallel[s.Benchmark] {
+			continue
+		}
+		if _, ok := byName[s.Name]; !ok {
+			order = append(order, s.Name)
+		}
+		byName[s.Name] = append(byName[s.Name], s)
+	}
+
+	var out []Scaling
+	for _, name := range order {
+		runs := byName[name]
+		if len(runs) < 2 {
+			continue
+		}
+		sort.Slice(runs, func(i, j int) bool { return runs[i].Procs < runs[j].Procs })
+
+		sc := Scaling{Name: name, Unit: "MB/s"}
+		for _, r := range runs {
+			if r.MBPerSec == 0 {
+				sc.Unit = "op/s"
+			}
+		}
+		for _, r := range runs {
+			if r.Procs < 1 || r.NsPerOp.Median <= 0 {
+				continue
+			}
+			tp := r.MBPerSec
+			if sc.Unit == "op/s" {
+				tp = 1e9 / r.NsPerOp.Median
+			}
+			sc.Points = append(sc.Points, ScalingPoint{
+				Procs:      r.Procs,
+				Throughput: tp,
+				PerCore:    tp / float64(r.Procs),
+			})
+		}
+		if len(sc.Points) < 2 {
+			continue
+		}
+		base := sc.Points[0].PerCore
+		for i := range sc.Points {
+			sc.Points[i].Efficiency = sc.Points[i].PerCore / base
+		}
+		sc.Flagged = sc.Last().Efficiency < scalingLimit
+		out = append(out, sc)
+	}
+	return out
+}
+
+// Scaling returns the scaling of the parallel benchmarks of the package
+// that ran with several -cpu values.
+func (r *packageRun) Scaling() []Scaling {
+	return scalingOf(r.Summaries, r.Parallel)
+}
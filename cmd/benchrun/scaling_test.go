@@ -0,0 +1,41 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestScalingOf(t *testing.T) {
+	sum := func(name string, procs int, mbs float64) Summary {
+		bench, _, _ := strings.Cut(name, "/")
+		return Summary{Name: name, Benchmark: bench, Procs: procs, MBPerSec: mbs, NsPerOp: Stats{Median: 1000}}
+	}
+	sums := []Summary{
+		sum("BenchmarkHashParallel/SHA256", 1, 400),
+		sum("BenchmarkHashParallel/SHA256", 2, 790),
+		sum("BenchmarkHashParallel/SHA256", 4, 1560),
+		sum("BenchmarkHashParallel/Shared", 1, 400),
+		sum("BenchmarkHashParallel/Shared", 2, 380),
+		sum("BenchmarkHashParallel/Shared", 4, 300),
+		sum("BenchmarkSingle", 1, 0),
+		// Sequential: one core at every -cpu value, the same throughput.
+		sum("BenchmarkSHA256", 1, 400),
+		sum("BenchmarkSHA256", 2, 400),
+		sum("BenchmarkSHA256", 4, 400),
+	}
+	got := scalingOf(sums, map[string]bool{"BenchmarkHashParallel": true, "BenchmarkSingle": true})
+	if len(got) != 2 {
+		t.Fatalf("got %d scalings, want 2", len(got))
+	}
+	sha, shared := got[0], got[1]
+	if sha.Flagged || !shared.Flagged {
+		t.Errorf("flagged = %v, %v, want false, true", sha.Flagged, shared.Flagged)
+	}
+	if last := sha.Last(); last.Procs != 4 || last.PerCore != 390 || math.Abs(last.Efficiency-0.975) > 1e-9 {
+		t.Errorf("last point = %+v", last)
+	}
+	if shared.Unit != "MB/s" {
+		t.Errorf("unit = %s, want MB/s", shared.Unit)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// median returns the median of xs, or NaN if xs is empty.
+func median(xs []float64) float64 {
+	if len(xs) == 0 {
+		return math.NaN()
+	}
+	s := append([]float64(nil), xs...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+// mannWhitneyU returns the two-sided p-value of the Mann-Whitney U test
+// for the samples x and y, the same test benchstat uses to decide whether
+// two sets of benchmark results differ.
+// Small samples without ties use the exact distribution of U, everything
+// else the normal approximation with tie and continuity correction.
+func mannWhitneyU(x, y []float64) float64 {
+	n1, n2 := len(x), len(y)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	type obs struct {
+		v     float64
+		fromX bool
+	}
+	all := make([]obs, 0, n1+n2)
+	for _, v := range x {
+		all = append(all, obs{v, true})
+	}
+	for _, v := range y {
+		all = append(all, obs{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Rank with ties getting the average rank, and collect the tie sizes.
+	var rankSumX, tieTerm float64
+	ties := false
+	for i := 0; i < len(all); {
+		j := i + 1
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromX {
+				rankSumX += rank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			ties = true
+			tieTerm += t*t*t - t
+		}
+		i = j
+	}
+
+	u := rankSumX - float64(n1*(n1+1))/2
+	if !ties && n1 <= 50 && n2 <= 50 {
+		return exactMannWhitneyP(u, n1, n2)
+	}
+
+	N := float64(n1 + n2)
+	mean := float64(n1*n2) / 2
+	variance := float64(n1*n2) / 12 * (N + 1 - tieTerm/(N*(N-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// exactMannWhitneyP returns the two-sided p-value of u from the exact
+// distribution of the U statistic without ties.
+func exactMannWhitneyP(u float64, n1, n2 int) float64 {
+	// counts[i][j][k] would be the number of arrangements of i x's and
+	// j y's with U == k; only the previous row is kept.
+	maxU := n1 * n2
+	prev := make([][]float64, n2+1)
+	for j := range prev {
+		prev[j] = make([]float64, maxU+1)
+		prev[j][0] = 1
+	}
+	for i := 1; i <= n1; i++ {
+		cur := make([][]float64, n2+1)
+		cur[0] = make([]float64, maxU+1)
+		cur[0][0] = 1
+		for j := 1; j <= n2; j++ {
+			cur[j] = make([]float64, maxU+1)
+			for k := 0; k <= i*j; k++ {
+				// The largest value is either an x, which beats all j y's,
+				// or a y, which beats none of the x's.
+				if k >= j {
+					cur[j][k] += prev[j][k-j]
+				}
+				cur[j][k] += cur[j-1][k]
+			}
+		}
+		prev = cur
+	}
+
+	dist := prev[n2]
+	var total float64
+	for _, c := range dist {
+		total += c
+	}
+
+	// Two-sided: probability of a U at least as far from the mean as u.
+	mean := float64(maxU) / 2
+	dev := math.Abs(u - mean)
+	var tail float64
+	for k, c := range dist {
+		if math.Abs(float64(k)-mean) >= dev-1e-9 {
+			tail += c
+		}
+	}
+	return math.Min(1, tail/total)
+}
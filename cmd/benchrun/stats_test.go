@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMedian(t *testing.T) {
+	if got := median([]float64{3, 1, 2}); got != 2 {
+		t.Errorf("median odd = %v, want 2", got)
+	}
+	if got := median([]float64{4, 1, 3, 2}); got != 2.5 {
+		t.Errorf("median even = %v, want 2.5", got)
+	}
+	if got := median(nil); !math.IsNaN(got) {
+		t.Errorf("median empty = %v, want NaN", got)
+	}
+}
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		name string
+		x, y []float64
+		want float64
+	}{
+		{"separated 5+5", []float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 2.0 / 252},
+		{"separated 3+3", []float64{1, 2, 3}, []float64{4, 5, 6}, 2.0 / 20},
+		{"single samples", []float64{1}, []float64{2}, 1},
+		{"identical", []float64{5, 5, 5, 5}, []float64{5, 5, 5, 5}, 1},
+		{"interleaved", []float64{1, 3, 5, 7}, []float64{2, 4, 6, 8}, 0.6857142857},
+	}
+	for _, tt := range tests {
+		if got := mannWhitneyU(tt.x, tt.y); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: p = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// With ties the normal approximation is used; clearly separated
+	// samples must still come out significant.
+	x := []float64{10, 10, 11, 11, 12, 12, 13, 13}
+	y := []float64{20, 20, 21, 21, 22, 22, 23, 23}
+	if p := mannWhitneyU(x, y); p >= 0.01 {
+		t.Errorf("tied separated samples: p = %v, want < 0.01", p)
+	}
+}
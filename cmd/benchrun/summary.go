@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Summary condenses the samples of one benchmark (go test -count N)
+// into the numbers shown in the README.
+type Summary struct {
+	Package     string             `json:"package"`
+	Name        string             `json:"name"`
+	Benchmark   string             `json:"benchmark"`
+	Sub         string             `json:"sub,omitempty"`
+	Procs       int                `json:"procs"`
+	NsPerOp     Stats              `json:"ns_per_op"`
+	BytesPerOp  float64            `json:"bytes_per_op"`  // median
+	AllocsPerOp float64            `json:"allocs_per_op"` // median
+	MBPerSec    float64            `json:"mb_per_s,omitempty"`
+	Metrics     map[string]float64 `json:"metrics,omitempty"` // median per custom unit
+	Noisy       bool               `json:"noisy,omitempty"`   // CV above the -maxcv limit
+}
+
+// Stats describes a set of samples after outlier rejection.
+type Stats struct {
+	Samples  int     `json:"samples"`  // samples kept
+	Outliers int     `json:"outliers"` // samples rejected by the Tukey fences
+	Median   float64 `json:"median"`
+	Min      float64 `json:"min"`
+	Max      float64 `json:"max"`
+	CILow    float64 `json:"ci_low"`  // 95% confidence interval of the median
+	CIHigh   float64 `json:"ci_high"` // (min and max for fewer than 6 samples)
+	CV       float64 `json:"cv"`      // coefficient of variation, stddev/mean
+}
+
+// summarize groups results by benchmark, in order of first appearance,
+// and computes the statistics of every group. A benchmark whose ns/op
+// varies by more than maxCV is marked as noisy.
+func summarize(results []Result, maxCV float64) []Summary {
+	type group struct {
+		first            Result
+		ns, bytes, alloc []float64
+		mbs              []float64
+		metrics          map[string][]float64
+	}
+	var order []string
+	groups := make(map[string]*group)
+	for _, r := range results {
+		key := fmt.Sprintf("%s/%s-%d", r.Package, r.Name, r.Procs)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{first: r}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.ns = append(g.ns, r.NsPerOp)
+		g.bytes = append(g.bytes, r.BytesPerOp)
+		g.alloc = append(g.alloc, r.AllocsPerOp)
+		if r.MBPerSec != 0 {
+			g.mbs = append(g.mbs, r.MBPerSec)
+		}
+		for unit, v := range r.Metrics {
+			if g.metrics == nil {
+				g.metrics = make(map[string][]float64)
+			}
+			g.metrics[unit] = append(g.metrics[unit], v)
+		}
+	}
+
+	summaries := make([]Summary, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		s := Summary{
+			Package:     g.first.Package,
+			Name:        g.first.Name,
+			Benchmark:   g.first.Benchmark,
+			Sub:         g.first.Sub,
+			Procs:       g.first.Procs,
+			NsPerOp:     computeStats(g.ns),
+			BytesPerOp:  median(g.bytes),
+			AllocsPerOp: median(g.alloc),
+		}
+		if len(g.mbs) > 0 {
+			s.MBPerSec = median(g.mbs)
+		}
+		for unit, vs := range g.metrics {
+			if s.Metrics == nil {
+				s.Metrics = make(map[string]float64)
+			}
+			s.Metrics[unit] = median(vs)
+		}
+		s.Noisy = s.NsPerOp.CV > maxCV
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+// computeStats rejects outliers outside of the Tukey fences
+// (1.5 times the interquartile range beyond the quartiles) and
+// describes the remaining samples.
+func computeStats(xs []float64) Stats {
+	kept := rejectOutliers(xs)
+	sort.Float64s(kept)
+
+	n := len(kept)
+	st := Stats{
+		Samples:  n,
+		Outliers: len(xs) - n,
+		Median:   median(kept),
+		Min:      kept[0],
+		Max:      kept[n-1],
+	}
+
+	var sum float64
+	for _, x := range kept {
+		sum += x
+	}
+	mean := sum / float64(n)
+	if n > 1 && mean != 0 {
+		var sq float64
+		for _, x := range kept {
+			sq += (x - mean) * (x - mean)
+		}
+		st.CV = math.Sqrt(sq/float64(n-1)) / mean
+	}
+
+	lo, hi := medianCI(n)
+	st.CILow, st.CIHigh = kept[lo], kept[hi]
+	return st
+}
+
+// rejectOutliers returns the samples within the Tukey fences.
+// Fewer than four samples are returned unchanged, the quartiles
+// of such small sets say nothing.
+func rejectOutliers(xs []float64) []float64 {
+	s := append([]float64(nil), xs...)
+	if len(s) < 4 {
+		return s
+	}
+	sort.Float64s(s)
+	q1, q3 := quantile(s, 0.25), quantile(s, 0.75)
+	iqr := q3 - q1
+	lo, hi := q1-1.5*iqr, q3+1.5*iqr
+
+	kept := s[:0]
+	for _, x := range s {
+		if x >= lo && x <= hi {
+			kept = append(kept, x)
+		}
+	}
+	return kept
+}
+
+// quantile returns the q-quantile of the sorted samples s
+// using linear interpolation between the closest ranks.
+func quantile(s []float64, q float64) float64 {
+	pos := q * float64(len(s)-1)
+	i := int(pos)
+	if i+1 >= len(s) {
+		return s[len(s)-1]
+	}
+	return s[i] + (pos-float64(i))*(s[i+1]-s[i])
+}
+
+// medianCI returns the indexes of the order statistics that enclose the
+// median of n sorted samples with at least 95% confidence. The interval
+// does not depend on the distribution of the samples; below 6 samples no
+// interval reaches 95% and the full range is returned.
+func medianCI(n int) (lo, hi int) {
+	// P(X < k) for X ~ Binomial(n, 1/2), accumulated until the two-sided
+	// error would exceed 5%.
+	k := 0
+	cum := 0.0
+	for {
+		next := cum + binomial(n, k)/math.Pow(2, float64(n))
+		if 2*next > 0.05 {
+			break
+		}
+		cum = next
+		k++
+	}
+	if k == 0 {
+		return 0, n - 1
+	}
+	return k - 1, n - k
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+	return r
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeStats(t *testing.T) {
+	st := computeStats([]float64{100, 102, 98, 101, 99, 100, 500})
+	if st.Samples != 6 || st.Outliers != 1 {
+		t.Errorf("samples/outliers = %d/%d, want 6/1", st.Samples, st.Outliers)
+	}
+	if st.Median != 100 || st.Min != 98 || st.Max != 102 {
+		t.Errorf("median/min/max = %v/%v/%v, want 100/98/102", st.Median, st.Min, st.Max)
+	}
+	if st.CILow != 98 || st.CIHigh != 102 {
+		t.Errorf("ci = %v…%v, want 98…102", st.CILow, st.CIHigh)
+	}
+	if st.CV <= 0 || st.CV > 0.02 {
+		t.Errorf("cv = %v, want a small positive value", st.CV)
+	}
+
+	single := computeStats([]float64{42})
+	if single.Median != 42 || single.CILow != 42 || single.CIHigh != 42 || single.CV != 0 {
+		t.Errorf("single sample stats = %+v", single)
+	}
+}
+
+func TestMedianCI(t *testing.T) {
+	tests := []struct{ n, lo, hi int }{
+		{1, 0, 0},
+		{5, 0, 4},
+		{6, 0, 5},
+		{10, 1, 8},
+		{20, 5, 14},
+	}
+	for _, tt := range tests {
+		if lo, hi := medianCI(tt.n); lo != tt.lo || hi != tt.hi {
+			t.Errorf("medianCI(%d) = %d, %d, want %d, %d", tt.n, lo, hi, tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	results := []Result{
+		{Package: "concat", Name: "BenchmarkConcatString", Procs: 8, NsPerOp: 1000, BytesPerOp: 2000, AllocsPerOp: 63},
+		{Package: "concat", Name: "BenchmarkConcatBuilder", Procs: 8, NsPerOp: 100, BytesPerOp: 248, AllocsPerOp: 5},
+		{Package: "concat", Name: "BenchmarkConcatString", Procs: 8, NsPerOp: 1500, BytesPerOp: 2000, AllocsPerOp: 63},
+		{Package: "concat", Name: "BenchmarkConcatBuilder", Procs: 8, NsPerOp: 101, BytesPerOp: 248, AllocsPerOp: 5},
+		{Package: "concat", Name: "BenchmarkConcatString", Procs: 8, NsPerOp: 500, BytesPerOp: 2000, AllocsPerOp: 63},
+		{Package: "concat", Name: "BenchmarkConcatBuilder", Procs: 8, NsPerOp: 99, BytesPerOp: 248, AllocsPerOp: 5},
+	}
+
+	sums := summarize(results, 0.05)
+	if len(sums) != 2 {
+		t.Fatalf("got %d summaries, want 2", len(sums))
+	}
+	if sums[0].Name != "BenchmarkConcatString" || sums[1].Name != "BenchmarkConcatBuilder" {
+		t.Errorf("order = %s, %s", sums[0].Name, sums[1].Name)
+	}
+	if !sums[0].Noisy || sums[1].Noisy {
+		t.Errorf("noisy = %v, %v, want true, false", sums[0].Noisy, sums[1].Noisy)
+	}
+	if sums[1].NsPerOp.Median != 100 || sums[1].AllocsPerOp != 5 || math.Abs(sums[1].NsPerOp.CV-0.01) > 1e-9 {
+		t.Errorf("builder summary = %+v", sums[1])
+	}
+}
+
+func TestSummarizeMetrics(t *testing.T) {
+	results := []Result{
+		{Package: "hash", Name: "BenchmarkQuality/FNV", Procs: 1, NsPerOp: 10, Metrics: map[string]float64{"collisions": 3}},
+		{Package: "hash", Name: "BenchmarkQuality/FNV", Procs: 1, NsPerOp: 11, Metrics: map[string]float64{"collisions": 5}},
+		{Package: "hash", Name: "BenchmarkQuality/FNV", Procs: 1, NsPerOp: 12, Metrics: map[string]float64{"collisions": 4}},
+	}
+	sums := summarize(results, 0.05)
+	if got := sums[0].Metrics["collisions"]; got != 4 {
+		t.Errorf("median collisions = %v, want 4", got)
+	}
+	run := &packageRun{Summaries: sums}
+	if units := run.MetricUnits(); len(units) != 1 || units[0] != "collisions" {
+		t.Errorf("MetricUnits() = %q", units)
+	}
+	if got := customMetric(sums[0], "chi2"); got != "" {
+		t.Errorf("customMetric of a missing unit = %q, want empty", got)
+	}
+}
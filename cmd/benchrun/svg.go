@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+)
+
+// The charts are written by hand instead of with a plotting library, they
+// only need a handful of elements and must not depend on external services.
+
+var chartPalette = []string{
+	"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f",
+	"#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac",
+}
+
+const (
+	chartWidth    = 760
+	chartFont     = `font-family="-apple-system,Segoe UI,Helvetica,Arial,sans-serif" font-size="12"`
+	chartCharWide = 7 // rough width of a character at font-size 12
+)
+
+func renderChart(c *chart) ([]byte, error) {
+	switch c.Kind {
+	case "bar":
+		return renderBarChart(c), nil
+	case "line":
+		return renderLineChart(c), nil
+	}
+	return nil, fmt.Errorf("unknown chart kind %q", c.Kind)
+}
+
+// axis maps values to pixels, on a log scale if the values span
+// several orders of magnitude.
+type axis struct {
+	log    bool
+	lo, hi float64 // domain, log10 of the bounds on a log axis
+	from   float64 // pixel of lo
+	to     float64 // pixel of hi
+}
+
+func newValueAxis(values []float64, from, to float64) axis {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsNaN(v) || v <= 0 {
+			continue
+		}
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if math.IsInf(lo, 1) {
+		return axis{lo: 0, hi: 1, from: from, to: to}
+	}
+	if hi/lo > 50 {
+		return axis{log: true, lo: math.Floor(math.Log10(lo)), hi: math.Ceil(math.Log10(hi)), from: from, to: to}
+	}
+	return axis{lo: 0, hi: niceCeil(hi), from: from, to: to}
+}
+
+func (a axis) pos(v float64) float64 {
+	if a.log {
+		v = math.Log10(v)
+	}
+	if a.hi == a.lo {
+		return a.from
+	}
+	return a.from + (v-a.lo)/(a.hi-a.lo)*(a.to-a.from)
+}
+
+// ticks returns the values to draw grid lines at.
+func (a axis) ticks() []float64 {
+	var ts []float64
+	if a.log {
+		for e := a.lo; e <= a.hi; e++ {
+			ts = append(ts, math.Pow(10, e))
+		}
+		return ts
+	}
+	step := a.hi / 5
+	for i := 0; i <= 5; i++ {
+		ts = append(ts, float64(i)*step)
+	}
+	return ts
+}
+
+// niceCeil rounds v up to 1, 2, 2.5 or 5 times a power of ten.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	exp := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		if m*exp >= v {
+			return m * exp
+		}
+	}
+	return 10 * exp
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func svgHeader(buf *bytes.Buffer, width, height float64, title string) {
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" %s>`+"\n",
+		width, height, width, height, chartFont)
+	fmt.Fprintf(buf, `<title>%s</title>`+"\n", escape(title))
+	fmt.Fprintf(buf, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	fmt.Fprintf(buf, `<text x="%.0f" y="20" font-size="14" font-weight="bold">%s</text>`+"\n", 10.0, escape(title))
+}
+
+// renderBarChart draws a horizontal bar per category and series,
+// the layout scales with the number of rows and the longest label.
+func renderBarChart(c *chart) []byte {
+	const (
+		top       = 40.0
+		barHeight = 14.0
+		barGap    = 2.0
+		rowGap    = 8.0
+		right     = 90.0 // room for the value labels
+	)
+
+	label := 0
+	for _, cat := range c.Categories {
+		label = max(label, len(cat))
+	}
+	left := math.Min(float64(label*chartCharWide+20), 320)
+
+	legend := 0.0
+	if len(c.Series) > 1 {
+		legend = 20
+	}
+	rowHeight := float64(len(c.Series))*(barHeight+barGap) - barGap + rowGap
+	plotTop := top + legend
+	plotBottom := plotTop + float64(len(c.Categories))*rowHeight
+	height := plotBottom + 40
+
+	var values []float64
+	for _, s := range c.Series {
+		values = append(values, s.Values...)
+	}
+	ax := newValueAxis(values, left, chartWidth-right)
+
+	var buf bytes.Buffer
+	svgHeader(&buf, chartWidth, height, c.Title)
+
+	if len(c.Series) > 1 {
+		x := left
+		for i, s := range c.Series {
+			fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="12" height="12" fill="%s"/>`+"\n", x, top-2, chartPalette[i%len(chartPalette)])
+			fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f">%s</text>`+"\n", x+16, top+8, escape(s.Name))
+			x += float64(len(s.Name)*chartCharWide) + 36
+		}
+	}
+
+	for _, t := range ax.ticks() {
+		x := ax.pos(t)
+		fmt.Fprintf(&buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e0e0e0"/>`+"\n", x, plotTop-4, x, plotBottom)
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#666">%s</text>`+"\n", x, plotBottom+16, formatMetric(t))
+	}
+	scale := c.Unit
+	if ax.log {
+		scale += ", log scale"
+	}
+	fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#666">%s</text>`+"\n", (left+chartWidth-right)/2, plotBottom+32, escape(scale))
+
+	for i, cat := range c.Categories {
+		y := plotTop + float64(i)*rowHeight
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="end">%s</text>`+"\n",
+			left-8, y+(rowHeight-rowGap)/2+4, escape(cat))
+		for s, series := range c.Series {
+			v := series.Values[i]
+			by := y + float64(s)*(barHeight+barGap)
+			if math.IsNaN(v) {
+				continue
+			}
+			w := math.Max(ax.pos(v)-left, 2)
+			fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s %s</title></rect>`+"\n",
+				left, by, w, barHeight, chartPalette[s%len(chartPalette)], escape(formatMetric(v)), escape(c.Unit))
+			fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f">%s</text>`+"\n", left+w+4, by+barHeight-3, formatMetric(v))
+		}
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// renderLineChart draws every series over the sizes in c.X on a log scale.
+// A size of zero, like an unbuffered channel, is drawn one decade
+// below the smallest positive size.
+func renderLineChart(c *chart) []byte {
+	const (
+		left   = 80.0
+		right  = 170.0 // legend
+		top    = 40.0
+		bottom = 50.0
+	)
+	// Grow with the legend, a sweep of every hash algorithm has dozens of lines.
+	height := math.Max(380, top+float64(len(c.Series))*18+bottom)
+
+	xs := make([]float64, len(c.X))
+	minX := math.Inf(1)
+	for _, x := range c.X {
+		if x > 0 {
+			minX = math.Min(minX, x)
+		}
+	}
+	if math.IsInf(minX, 1) {
+		minX = 1
+	}
+	for i, x := range c.X {
+		if x <= 0 {
+			x = minX / 10
+		}
+		xs[i] = math.Log10(x)
+	}
+	xAxis := axis{lo: xs[0], hi: xs[len(xs)-1], from: left, to: chartWidth - right}
+
+	var values []float64
+	for _, s := range c.Series {
+		values = append(values, s.Values...)
+	}
+	yAxis := newValueAxis(values, height-bottom, top)
+
+	var buf bytes.Buffer
+	svgHeader(&buf, chartWidth, height, c.Title)
+
+	for _, t := range yAxis.ticks() {
+		y := yAxis.pos(t)
+		fmt.Fprintf(&buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e0e0e0"/>`+"\n", left, y, chartWidth-right, y)
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="end" fill="#666">%s</text>`+"\n", left-6, y+4, formatMetric(t))
+	}
+	for i, cat := range c.Categories {
+		x := xAxis.pos(xs[i])
+		fmt.Fprintf(&buf, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e0e0e0"/>`+"\n", x, top, x, height-bottom)
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#666">%s</text>`+"\n", x, height-bottom+16, escape(cat))
+	}
+	scale := c.Unit
+	if yAxis.log {
+		scale += ", log scale"
+	}
+	fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f" text-anchor="middle" fill="#666">size (log scale)</text>`+"\n", (left+chartWidth-right)/2, height-bottom+34)
+	fmt.Fprintf(&buf, `<text transform="translate(16 %.1f) rotate(-90)" text-anchor="middle" fill="#666">%s</text>`+"\n", (top+height-bottom)/2, escape(scale))
+
+	for s, series := range c.Series {
+		color := chartPalette[s%len(chartPalette)]
+		var path bytes.Buffer
+		pen := "M"
+		for i, v := range series.Values {
+			if math.IsNaN(v) || (yAxis.log && v <= 0) {
+				pen = "M"
+				continue
+			}
+			fmt.Fprintf(&path, "%s%.1f %.1f ", pen, xAxis.pos(xs[i]), yAxis.pos(v))
+			pen = "L"
+		}
+		fmt.Fprintf(&buf, `<path d="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", path.String(), color)
+		for i, v := range series.Values {
+			if math.IsNaN(v) || (yAxis.log && v <= 0) {
+				continue
+			}
+			point := c.Categories[i]
+			if series.Name != "" {
+				point = series.Name + " " + point
+			}
+			fmt.Fprintf(&buf, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"><title>%s: %s %s</title></circle>`+"\n",
+				xAxis.pos(xs[i]), yAxis.pos(v), color, escape(point), formatMetric(v), escape(c.Unit))
+		}
+
+		// A single unnamed line is explained by the title already.
+		if series.Name == "" {
+			continue
+		}
+		ly := top + 6 + float64(s)*18
+		fmt.Fprintf(&buf, `<rect x="%.1f" y="%.1f" width="12" height="12" fill="%s"/>`+"\n", chartWidth-right+14, ly-10, color)
+		fmt.Fprintf(&buf, `<text x="%.1f" y="%.1f">%s</text>`+"\n", chartWidth-right+30, ly, escape(series.Name))
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
@@ -9,104 +9,78 @@ import (
 	"bytes"
 	"strings"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 const concatParts = 64
 
-var concatResult string
-
-func BenchmarkConcatString(b *testing.B) {
-	b.ReportAllocs()
-
-	for n := 0; n < b.N; n++ {
-		var str string
-		for i := 0; i < concatParts; i++ {
-			str += "x"
-		}
-		concatResult = str
+func concatString(parts int) string {
+	var str string
+	for i := 0; i < parts; i++ {
+		str += "x"
 	}
+	return str
 }
 
-func BenchmarkConcatBuffer(b *testing.B) {
-	b.ReportAllocs()
-
-	for n := 0; n < b.N; n++ {
-		var buffer bytes.Buffer
-		for i := 0; i < concatParts; i++ {
-			buffer.WriteString("x")
-		}
-		concatResult = buffer.String()
+func concatBuffer(parts int) string {
+	var buffer bytes.Buffer
+	for i := 0; i < parts; i++ {
+		buffer.WriteString("x")
 	}
+	return buffer.String()
 }
 
-func BenchmarkConcatBuilder(b *testing.B) {
-	b.ReportAllocs()
+func concatBuilder(parts int) string {
+	var builder strings.Builder
+	for i := 0; i < parts; i++ {
+		builder.WriteString("x")
+	}
+	return builder.String()
+}
 
-	for n := 0; n < b.N; n++ {
-		var builder strings.Builder
-		for i := 0; i < concatParts; i++ {
-			builder.WriteString("x")
-		}
-		concatResult = builder.String()
+func concatBuilderGrow(parts int) string {
+	var builder strings.Builder
+	builder.Grow(parts)
+	for i := 0; i < parts; i++ {
+		builder.WriteString("x")
 	}
+	return builder.String()
 }
 
-func BenchmarkConcatBuilderGrow(b *testing.B) {
-	b.ReportAllocs()
+var concatFamily = &benchutil.Family[int, string]{
+	Reference: func(parts int) string { return strings.Repeat("x", parts) },
+	Variants: []benchutil.Variant[int, string]{
+		{Name: "String", Fn: concatString},
+		{Name: "Buffer", Fn: concatBuffer},
+		{Name: "Builder", Fn: concatBuilder},
+		{Name: "BuilderGrow", Fn: concatBuilderGrow},
+	},
+	Inputs: func(g *benchutil.Gen) []int {
+		return append([]int{0, 1, concatParts}, g.Ints(20, 0, 1000)...)
+	},
+}
 
-	for n := 0; n < b.N; n++ {
-		var builder strings.Builder
-		builder.Grow(concatParts)
-		for i := 0; i < concatParts; i++ {
-			builder.WriteString("x")
-		}
-		concatResult = builder.String()
-	}
+func TestConcat(t *testing.T) {
+	concatFamily.Verify(t)
 }
 
-func BenchmarkConcat(b *testing.B) {
-	b.Run("String", func(b *testing.B) {
-		b.ReportAllocs()
-		for n := 0; n < b.N; n++ {
-			var str string
-			for i := 0; i < concatParts; i++ {
-				str += "x"
-			}
-			concatResult = str
-		}
-	})
+func BenchmarkConcatString(b *testing.B) {
+	concatFamily.BenchmarkVariant(b, "String", concatParts)
+}
 
-	b.Run("Buffer", func(b *testing.B) {
-		b.ReportAllocs()
-		for n := 0; n < b.N; n++ {
-			var buffer bytes.Buffer
-			for i := 0; i < concatParts; i++ {
-				buffer.WriteString("x")
-			}
-			concatResult = buffer.String()
-		}
-	})
+func BenchmarkConcatBuffer(b *testing.B) {
+	concatFamily.BenchmarkVariant(b, "Buffer", concatParts)
+}
 
-	b.Run("Builder", func(b *testing.B) {
-		b.ReportAllocs()
-		for n := 0; n < b.N; n++ {
-			var builder strings.Builder
-			for i := 0; i < concatParts; i++ {
-				builder.WriteString("x")
-			}
-			concatResult = builder.String()
-		}
-	})
+func BenchmarkConcatBuilder(b *testing.B) {
+	concatFamily.BenchmarkVariant(b, "Builder", concatParts)
+}
 
-	b.Run("BuilderGrow", func(b *testing.B) {
-		b.ReportAllocs()
-		for n := 0; n < b.N; n++ {
-			var builder strings.Builder
-			builder.Grow(concatParts)
-			for i := 0; i < concatParts; i++ {
-				builder.WriteString("x")
-			}
-			concatResult = builder.String()
-		}
-	})
+func BenchmarkConcatBuilderGrow(b *testing.B) {
+	concatFamily.BenchmarkVariant(b, "BuilderGrow", concatParts)
+}
+
+func BenchmarkConcat(b *testing.B) {
+	concatFamily.Benchmark(b, concatParts)
 }
@@ -0,0 +1,318 @@
+package concat
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// field is a column of a row, a string or a number.
+type field struct {
+	s   string
+	n   int64
+	num bool
+}
+
+// row is a record joined with commas, like a CSV line or a log entry. The
+// format and arguments of fmt.Sprintf are prepared with it.
+type row struct {
+	fields []field
+	format string
+	args   []any
+}
+
+func newRow(fields []field) *row {
+	r := &row{fields: fields}
+	verbs := make([]string, len(fields))
+	for i, f := range fields {
+		if f.num {
+			verbs[i] = "%d"
+			r.args = append(r.args, f.n)
+		} else {
+			verbs[i] = "%s"
+			r.args = append(r.args, f.s)
+		}
+	}
+	r.format = strings.Join(verbs, ",")
+	return r
+}
+
+// text returns f as a string.
+func (f field) text() string {
+	if f.num {
+		return strconv.FormatInt(f.n, 10)
+	}
+	return f.s
+}
+
+// sizeHint is the length of the joined row if every number takes 20
+// digits, what a caller who does not format twice can pass to Grow.
+func (r *row) sizeHint() int {
+	n := len(r.fields)
+	for _, f := range r.fields {
+		if f.num {
+			n += 20
+		} else {
+			n += len(f.s)
+		}
+	}
+	return n
+}
+
+// randomRow returns n fields of 1 to 24 characters, every fourth one a
+// number.
+func randomRow(g *benchutil.Gen, n int) *row {
+	fields := make([]field, n)
+	for i := range fields {
+		if i%4 == 3 {
+			fields[i] = field{n: int64(g.Int(-1e9, 1e9)), num: true}
+		} else {
+			fields[i] = field{s: g.ASCII(g.Int(1, 25))}
+		}
+	}
+	return newRow(fields)
+}
+
+// placeholderRow returns n copies of "?", the placeholders of an SQL
+// statement, the one row strings.Repeat can build.
+func placeholderRow(n int) *row {
+	fields := make([]field, n)
+	for i := range fields {
+		fields[i] = field{s: "?"}
+	}
+	return newRow(fields)
+}
+
+func joinPlus(r *row) string {
+	var s string
+	for i, f := range r.fields {
+		if i > 0 {
+			s += ","
+		}
+		s += f.text()
+	}
+	return s
+}
+
+func joinSprintf(r *row) string {
+	return fmt.Sprintf(r.format, r.args...)
+}
+
+func joinJoin(r *row) string {
+	parts := make([]string, len(r.fields))
+	for i, f := range r.fields {
+		parts[i] = f.text()
+	}
+	return strings.Join(parts, ",")
+}
+
+func joinBuilder(r *row) string {
+	var sb strings.Builder
+	for i, f := range r.fields {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(f.text())
+	}
+	return sb.String()
+}
+
+func joinBuilderGrow(r *row) string {
+	var sb strings.Builder
+	sb.Grow(r.sizeHint())
+	for i, f := range r.fields {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(f.text())
+	}
+	return sb.String()
+}
+
+func writeRow(buf *bytes.Buffer, r *row) {
+	for i, f := range r.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(f.text())
+	}
+}
+
+func joinBuffer(r *row) string {
+	var buf bytes.Buffer
+	writeRow(&buf, r)
+	return buf.String()
+}
+
+// appendRow appends the row with every number formatted to a string
+// first.
+func appendRow(dst []byte, r *row) []byte {
+	for i, f := range r.fields {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, f.text()...)
+	}
+	return dst
+}
+
+// appendRowStrconv appends the row with strconv.AppendInt, numbers go
+// into the buffer without a string in between.
+func appendRowStrconv(dst []byte, r *row) []byte {
+	for i, f := range r.fields {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		if f.num {
+			dst = strconv.AppendInt(dst, f.n, 10)
+		} else {
+			dst = append(dst, f.s...)
+		}
+	}
+	return dst
+}
+
+func joinRepeat(r *row) string {
+	if len(r.fields) == 0 {
+		return ""
+	}
+	return strings.Repeat(r.fields[0].s+",", len(r.fields)-1) + r.fields[0].s
+}
+
+// The pools hold the buffers of the pooled variants. A strings.Builder
+// cannot be pooled: String hands its buffer to the result, so the next
+// user must start with a new one.
+var (
+	bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+	bytesPool  = sync.Pool{New: func() any {
+		b := make([]byte, 0, 256)
+		return &b
+	}}
+)
+
+func joinBufferPool(r *row) string {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	writeRow(buf, r)
+	s := buf.String()
+	bufferPool.Put(buf)
+	return s
+}
+
+func joinAppendPool(appendFn func([]byte, *row) []byte) func(*row) string {
+	return func(r *row) string {
+		bp := bytesPool.Get().(*[]byte)
+		*bp = appendFn((*bp)[:0], r)
+		s := string(*bp)
+		bytesPool.Put(bp)
+		return s
+	}
+}
+
+var joinFamily = &benchutil.Family[*row, string]{
+	Reference: func(r *row) string {
+		parts := make([]string, len(r.fields))
+		for i, f := range r.fields {
+			if f.num {
+				parts[i] = fmt.Sprint(f.n)
+			} else {
+				parts[i] = f.s
+			}
+		}
+		return strings.Join(parts, ",")
+	},
+	Variants: []benchutil.Variant[*row, string]{
+		{Name: "Plus", Fn: joinPlus},
+		{Name: "Sprintf", Fn: joinSprintf},
+		{Name: "Join", Fn: joinJoin},
+		{Name: "Builder", Fn: joinBuilder},
+		{Name: "BuilderGrow", Fn: joinBuilderGrow},
+		{Name: "Buffer", Fn: joinBuffer},
+		{Name: "BufferPool", Fn: joinBufferPool},
+		{Name: "Append", Fn: func(r *row) string { return string(appendRow(nil, r)) }},
+		{Name: "AppendPool", Fn: joinAppendPool(appendRow)},
+		{Name: "StrconvAppend", Fn: func(r *row) string { return string(appendRowStrconv(nil, r)) }},
+		{Name: "StrconvAppendPool", Fn: joinAppendPool(appendRowStrconv)},
+		{Name: "Repeat", Fn: joinRepeat, Accepts: func(r *row) bool {
+			for _, f := range r.fields {
+				if f.num || f.s != r.fields[0].s {
+					return false
+				}
+			}
+			return true
+		}},
+	},
+	Inputs: func(g *benchutil.Gen) []*row {
+		in := []*row{newRow(nil), placeholderRow(1), placeholderRow(7), newRow([]field{{s: "%d"}, {n: -1, num: true}})}
+		for range 20 {
+			in = append(in, randomRow(g, g.Int(1, 100)))
+		}
+		return in
+	},
+}
+
+func TestJoin(t *testing.T) {
+	joinFamily.Verify(t)
+}
+
+// joinFields are the numbers of fields of the rows, from a key-value
+// pair to a wide table.
+var joinFields = []int{2, 8, 32, 128}
+
+// BenchmarkJoin joins rows of random fields. Repeat needs identical
+// fields and is skipped, BenchmarkJoinPlaceholders times it.
+func BenchmarkJoin(b *testing.B) {
+	benchmarkJoin(b, func(n int) *row { return randomRow(benchutil.NewGen(benchutil.Seed), n) })
+}
+
+// BenchmarkJoinPlaceholders joins the placeholders of an SQL statement.
+func BenchmarkJoinPlaceholders(b *testing.B) {
+	benchmarkJoin(b, placeholderRow)
+}
+
+func benchmarkJoin(b *testing.B, rowOf func(n int) *row) {
+	for _, v := range joinFamily.Variants {
+		b.Run(v.Name, func(b *testing.B) {
+			for _, n := range joinFields {
+				b.Run(fmt.Sprintf("fields=%d", n), func(b *testing.B) {
+					r := rowOf(n)
+					if v.Accepts != nil && !v.Accepts(r) {
+						b.Skip("the fields are not all the same")
+					}
+					joinFamily.BenchmarkVariant(b, v.Name, r)
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkJoinParallel joins rows of 32 random fields on all procs. The
+// pooled variants share their buffers between the goroutines through a
+// sync.Pool, the others allocate per call and stress the allocator.
+func BenchmarkJoinParallel(b *testing.B) {
+	joinFamily.Verify(b)
+	r := randomRow(benchutil.NewGen(benchutil.Seed), 32)
+	want := joinFamily.Reference(r)
+	for _, v := range joinFamily.Variants {
+		if v.Accepts != nil && !v.Accepts(r) {
+			continue
+		}
+		b.Run(v.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				var s string
+				for pb.Next() {
+					s = v.Fn(r)
+				}
+				if s != "" && s != want {
+					b.Errorf("%s = %q, want %q", v.Name, s, want)
+				}
+			})
+		})
+	}
+}
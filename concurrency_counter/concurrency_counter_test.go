@@ -6,6 +6,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 // Benchmarks comparing common concurrency counter patterns.
@@ -49,13 +51,19 @@ func BenchmarkRWMutexReadParallel(b *testing.B) {
 	// populate counter
 	counter = 42
 
+	// Every goroutine sums what it reads and adds it to total once, a
+	// Sink is not safe for concurrent use.
+	var total atomic.Int64
 	b.RunParallel(func(pb *testing.PB) {
+		var sum int64
 		for pb.Next() {
 			mu.RLock()
-			_ = counter
+			sum += counter
 			mu.RUnlock()
 		}
+		total.Add(sum)
 	})
+	benchutil.Int64Sink.Store(total.Load())
 }
 
 func BenchmarkAtomicParallel(b *testing.B) {
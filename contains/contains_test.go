@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 // strings.Contains
@@ -137,38 +139,70 @@ func BenchmarkMatchNot(b *testing.B) {
 	}
 }
 
-// BenchmarkContainsMethods benchmarks different methods to check substring presence.
-func BenchmarkContainsMethods(b *testing.B) {
-	b.Run("Strings.Contains", func(b *testing.B) {
-		str := "Lorem Ipsum"
-		substr := "em Ip"
-		for n := 0; n < b.N; n++ {
-			_ = strings.Contains(str, substr)
-			_ = strings.Contains(str, "Dolor")
-		}
-	})
+// containsCase is a haystack and a needle in all forms the variants need,
+// prepared outside of the timed loop.
+type containsCase struct {
+	s, substr string
+	b, subb   []byte
+	pattern   string
+	re        *regexp.Regexp
+}
 
-	b.Run("Bytes.Contains", func(b *testing.B) {
-		str := []byte("Lorem Ipsum")
-		substr := []byte("em Ip")
-		for n := 0; n < b.N; n++ {
-			_ = bytes.Contains(str, substr)
-			_ = bytes.Contains(str, []byte("Dolor"))
-		}
-	})
+func newContainsCase(s, substr string) containsCase {
+	pattern := regexp.QuoteMeta(substr)
+	return containsCase{
+		s: s, substr: substr,
+		b: []byte(s), subb: []byte(substr),
+		pattern: pattern,
+		re:      regexp.MustCompile(pattern),
+	}
+}
 
-	b.Run("RegexMatchString", func(b *testing.B) {
-		re := regexp.MustCompile(`em Ip`)
-		for n := 0; n < b.N; n++ {
-			_ = re.MatchString("Lorem Ipsum")
-			_ = re.MatchString("Dolor")
-		}
-	})
+// containsPair is a search that matches and one that does not,
+// both are done in every iteration.
+type containsPair [2]containsCase
+
+func containsEach(fn func(containsCase) bool) func(containsPair) [2]bool {
+	return func(p containsPair) [2]bool {
+		return [2]bool{fn(p[0]), fn(p[1])}
+	}
+}
 
-	b.Run("RegexMatch", func(b *testing.B) {
-		for n := 0; n < b.N; n++ {
-			_, _ = regexp.MatchString(`em Ip`, "Lorem Ipsum")
-			_, _ = regexp.MatchString(`em Ip`, "Dolor")
+var containsFamily = &benchutil.Family[containsPair, [2]bool]{
+	Reference: containsEach(func(c containsCase) bool { return strings.Contains(c.s, c.substr) }),
+	Variants: []benchutil.Variant[containsPair, [2]bool]{
+		{Name: "Strings.Contains", Fn: containsEach(func(c containsCase) bool { return strings.Contains(c.s, c.substr) })},
+		{Name: "Bytes.Contains", Fn: containsEach(func(c containsCase) bool { return bytes.Contains(c.b, c.subb) })},
+		{Name: "RegexMatchString", Fn: containsEach(func(c containsCase) bool { return c.re.MatchString(c.s) })},
+		{Name: "RegexMatch", Fn: containsEach(func(c containsCase) bool {
+			matched, _ := regexp.MatchString(c.pattern, c.s)
+			return matched
+		})},
+	},
+	Inputs: func(g *benchutil.Gen) []containsPair {
+		pairs := []containsPair{
+			{newContainsCase("Lorem Ipsum", "em Ip"), newContainsCase("Lorem Ipsum", "Dolor")},
+			{newContainsCase("", ""), newContainsCase("a.c", "a.c")},
+			{newContainsCase("abc", "a.c"), newContainsCase("x(y)", "(y)")},
+		}
+		for range 100 {
+			s := g.ASCII(g.Int(0, 64))
+			i := g.Int(0, len(s)+1)
+			j := g.Int(i, len(s)+1)
+			pairs = append(pairs, containsPair{newContainsCase(s, s[i:j]), newContainsCase(s, g.ASCII(g.Int(1, 8)))})
 		}
+		return pairs
+	},
+}
+
+func TestContainsMethods(t *testing.T) {
+	containsFamily.Verify(t)
+}
+
+// BenchmarkContainsMethods benchmarks different methods to check substring presence.
+func BenchmarkContainsMethods(b *testing.B) {
+	containsFamily.Benchmark(b, containsPair{
+		newContainsCase("Lorem Ipsum", "em Ip"),
+		newContainsCase("Lorem Ipsum", "Dolor"),
 	})
 }
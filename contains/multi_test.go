@@ -0,0 +1,341 @@
+package contains
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// multiMatcher reports whether any of a set of non-empty patterns occurs
+// in s.
+type multiMatcher interface {
+	Match(s string) bool
+}
+
+type containsLoop []string
+
+func (l containsLoop) Match(s string) bool {
+	for _, p := range l {
+		if strings.Contains(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+type regexpAlternation struct{ re *regexp.Regexp }
+
+func newRegexpAlternation(patterns []string) multiMatcher {
+	if len(patterns) == 0 {
+		return regexpAlternation{regexp.MustCompile(`[^\x00-\x{10FFFF}]`)} // matches nothing
+	}
+	quoted := make([]string, len(patterns))
+	for i, p := range patterns {
+		quoted[i] = regexp.QuoteMeta(p)
+	}
+	return regexpAlternation{regexp.MustCompile(strings.Join(quoted, "|"))}
+}
+
+func (r regexpAlternation) Match(s string) bool {
+	return r.re.MatchString(s)
+}
+
+// replacerDetect replaces every pattern with nothing, any pattern occurs
+// if the result is shorter. strings.Replacer builds its trie on first
+// use, newReplacerDetect forces it so the build is not timed as a match.
+type replacerDetect struct{ r *strings.Replacer }
+
+func newReplacerDetect(patterns []string) multiMatcher {
+	oldnew := make([]string, 0, 2*len(patterns))
+	for _, p := range patterns {
+		oldnew = append(oldnew, p, "")
+	}
+	r := strings.NewReplacer(oldnew...)
+	r.Replace("")
+	return replacerDetect{r}
+}
+
+func (r replacerDetect) Match(s string) bool {
+	return len(r.r.Replace(s)) != len(s)
+}
+
+// ahoCorasick is an Aho-Corasick automaton compiled to a DFA: the trie of
+// the patterns with every missing edge replaced by the edge of the
+// failure link, so matching is one table lookup per byte. Bytes are
+// mapped to classes first, the bytes of the patterns and one class for
+// all others, which keeps the table small for thousands of patterns.
+type ahoCorasick struct {
+	class   [256]int32
+	classes int     // number of classes, 0 is the class of all other bytes
+	delta   []int32 // next state, indexed by state*classes+class
+	match   []bool  // a pattern ends in the state or in one of its failure links
+}
+
+func newAhoCorasick(patterns []string) *ahoCorasick {
+	a := &ahoCorasick{classes: 1}
+	for _, p := range patterns {
+		for i := 0; i < len(p); i++ {
+			if a.class[p[i]] == 0 {
+				a.class[p[i]] = int32(a.classes)
+				a.classes++
+			}
+		}
+	}
+	nc := a.classes
+	newState := func() int32 {
+		for range nc {
+			a.delta = append(a.delta, -1)
+		}
+		a.match = append(a.match, false)
+		return int32(len(a.match) - 1)
+	}
+
+	// The trie, -1 for a missing edge.
+	newState()
+	for _, p := range patterns {
+		s := int32(0)
+		for i := 0; i < len(p); i++ {
+			e := int(s)*nc + int(a.class[p[i]])
+			if a.delta[e] < 0 {
+				t := newState()
+				a.delta[e] = t
+			}
+			s = a.delta[e]
+		}
+		a.match[s] = true
+	}
+
+	// The failure links in breadth-first order, a state's link is
+	// shallower than the state and complete by the time it is needed.
+	fail := make([]int32, len(a.match))
+	var queue []int32
+	for c := 0; c < nc; c++ {
+		if t := a.delta[c]; t < 0 {
+			a.delta[c] = 0
+		} else {
+			queue = append(queue, t)
+		}
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		f := int(fail[s])
+		a.match[s] = a.match[s] || a.match[f]
+		for c := 0; c < nc; c++ {
+			e := int(s)*nc + c
+			if t := a.delta[e]; t < 0 {
+				a.delta[e] = a.delta[f*nc+c]
+			} else {
+				fail[t] = a.delta[f*nc+c]
+				queue = append(queue, t)
+			}
+		}
+	}
+	return a
+}
+
+func (a *ahoCorasick) Match(s string) bool {
+	st := int32(0)
+	for i := 0; i < len(s); i++ {
+		st = a.delta[int(st)*a.classes+int(a.class[s[i]])]
+		if a.match[st] {
+			return true
+		}
+	}
+	return false
+}
+
+// scrub overwrites the last byte of every match in text with a space,
+// which must not occur in a pattern, until no pattern is left.
+func (a *ahoCorasick) scrub(text []byte) {
+	st := int32(0)
+	for i := range text {
+		st = a.delta[int(st)*a.classes+int(a.class[text[i]])]
+		if a.match[st] {
+			text[i] = ' '
+			st = 0 // a space leads to the root from every state
+		}
+	}
+}
+
+// multiMatchers are the strategies by name, each built once per pattern
+// set.
+var multiMatchers = []struct {
+	name  string
+	build func(patterns []string) multiMatcher
+}{
+	{"ContainsLoop", func(patterns []string) multiMatcher { return containsLoop(patterns) }},
+	{"RegexpAlternation", newRegexpAlternation},
+	{"Replacer", newReplacerDetect},
+	{"AhoCorasick", func(patterns []string) multiMatcher { return newAhoCorasick(patterns) }},
+}
+
+// multiCase is a haystack and a pattern set with every matcher built.
+type multiCase struct {
+	haystack string
+	patterns []string
+	matchers []multiMatcher // by index of multiMatchers
+}
+
+func newMultiCase(haystack string, patterns []string) *multiCase {
+	c := &multiCase{haystack: haystack, patterns: patterns}
+	for _, m := range multiMatchers {
+		c.matchers = append(c.matchers, m.build(patterns))
+	}
+	return c
+}
+
+var multiFamily = func() *benchutil.Family[*multiCase, bool] {
+	f := &benchutil.Family[*multiCase, bool]{
+		Reference: func(c *multiCase) bool {
+			for _, p := range c.patterns {
+				for i := 0; i+len(p) <= len(c.haystack); i++ {
+					if c.haystack[i:i+len(p)] == p {
+						return true
+					}
+				}
+			}
+			return false
+		},
+		Inputs: func(g *benchutil.Gen) []*multiCase {
+			classic := []string{"he", "she", "his", "hers"}
+			in := []*multiCase{
+				newMultiCase("ushers", classic), newMultiCase("ahishe", classic),
+				newMultiCase("hxsx rs", classic), newMultiCase("", classic),
+				newMultiCase("anything", nil), newMultiCase("a+b", []string{"a+b", "(", "|"}),
+			}
+			for range 200 {
+				patterns := make([]string, g.Int(1, 8))
+				for i := range patterns {
+					patterns[i] = smallAlphabet(g, g.Int(1, 6))
+				}
+				in = append(in, newMultiCase(smallAlphabet(g, g.Int(0, 20)), patterns))
+			}
+			return in
+		},
+	}
+	for i, m := range multiMatchers {
+		v := benchutil.Variant[*multiCase, bool]{
+			Name: m.name,
+			Fn:   func(c *multiCase) bool { return c.matchers[i].Match(c.haystack) },
+		}
+		if m.name == "RegexpAlternation" {
+			v.Accepts = func(c *multiCase) bool { return len(c.patterns)*len(c.haystack) <= regexpWork }
+		}
+		f.Variants = append(f.Variants, v)
+	}
+	return f
+}()
+
+// regexpWork limits the patterns times the haystack bytes of the regexp
+// alternation. Its NFA steps through every alternative per byte, 10,000
+// patterns over 64 KiB take over a minute per op.
+const regexpWork = 1 << 23
+
+func TestMultiPattern(t *testing.T) {
+	multiFamily.Verify(t)
+}
+
+// keywords returns n distinct lowercase words of 4 to 12 letters.
+func keywords(g *benchutil.Gen, n int) []string {
+	seen := make(map[string]bool, n)
+	words := make([]string, 0, n)
+	for len(words) < n {
+		w := make([]byte, g.Int(4, 13))
+		for i := range w {
+			w[i] = byte('a' + g.Int(0, 26))
+		}
+		if !seen[string(w)] {
+			seen[string(w)] = true
+			words = append(words, string(w))
+		}
+	}
+	return words
+}
+
+// keywordText returns n bytes of lowercase words that contain the last
+// keyword at the end and no keyword before it, so every matcher scans the
+// whole text.
+func keywordText(g *benchutil.Gen, patterns []string, n int) string {
+	text := make([]byte, n)
+	for i := range text {
+		if g.Int(0, 6) == 0 {
+			text[i] = ' '
+		} else {
+			text[i] = byte('a' + g.Int(0, 26))
+		}
+	}
+	newAhoCorasick(patterns).scrub(text)
+	last := patterns[len(patterns)-1]
+	copy(text[n-len(last):], last)
+	return string(text)
+}
+
+// multiCounts are the sizes of the pattern sets, from a handful of
+// keywords to a block list.
+var multiCounts = []int{10, 100, 1000, 10_000}
+
+// multiSizes are the haystacks, from a log line to a request body.
+var multiSizes = []int{256, 4 << 10, 64 << 10}
+
+var multiCases = make(map[[2]int]*multiCase)
+
+// multiCaseOf returns the case of count keywords and a haystack of n
+// bytes, built once.
+func multiCaseOf(count, n int) *multiCase {
+	if c, ok := multiCases[[2]int{count, n}]; ok {
+		return c
+	}
+	g := benchutil.NewGen(benchutil.Seed)
+	patterns := keywords(g, count)
+	c := newMultiCase(keywordText(g, patterns, n), patterns)
+	multiCases[[2]int{count, n}] = c
+	return c
+}
+
+// BenchmarkMultiPattern matches pattern sets of every size against
+// haystacks of every size, with the matchers built beforehand. Only the
+// last keyword occurs, at the end of the haystack. The regexp alternation
+// is skipped above regexpWork.
+func BenchmarkMultiPattern(b *testing.B) {
+	for _, n := range multiSizes {
+		b.Run("haystack="+benchutil.SizeName(n), func(b *testing.B) {
+			for _, v := range multiFamily.Variants {
+				b.Run(v.Name, func(b *testing.B) {
+					for _, count := range multiCounts {
+						b.Run(fmt.Sprintf("patterns=%d", count), func(b *testing.B) {
+							c := multiCaseOf(count, n)
+							if v.Accepts != nil && !v.Accepts(c) {
+								b.Skip("too slow for this many patterns and bytes")
+							}
+							b.SetBytes(int64(n))
+							multiFamily.BenchmarkVariant(b, v.Name, c)
+						})
+					}
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkMultiPatternBuild is the one-time cost of building each
+// matcher. The ContainsLoop has nothing to build and is left out.
+func BenchmarkMultiPatternBuild(b *testing.B) {
+	for _, m := range multiMatchers[1:] {
+		b.Run(m.name, func(b *testing.B) {
+			for _, count := range multiCounts {
+				b.Run(fmt.Sprintf("patterns=%d", count), func(b *testing.B) {
+					patterns := keywords(benchutil.NewGen(benchutil.Seed), count)
+					b.ReportAllocs()
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						benchutil.AnySink.Store(m.build(patterns))
+					}
+				})
+			}
+		})
+	}
+}
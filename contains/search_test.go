@@ -0,0 +1,376 @@
+package contains
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// horspool is the Boyer-Moore-Horspool searcher: it compares the last
+// byte of the window first and shifts by the distance of that byte from
+// the end of the needle.
+type horspool struct {
+	needle string
+	shift  [256]int
+}
+
+func newHorspool(needle string) *horspool {
+	h := &horspool{needle: needle}
+	for i := range h.shift {
+		h.shift[i] = len(needle)
+	}
+	for i := 0; i < len(needle)-1; i++ {
+		h.shift[needle[i]] = len(needle) - 1 - i
+	}
+	return h
+}
+
+func (h *horspool) Index(s string) int {
+	m := len(h.needle)
+	if m == 0 {
+		return 0
+	}
+	last := m - 1
+	for i := 0; i+m <= len(s); i += h.shift[s[i+last]] {
+		if s[i+last] == h.needle[last] && s[i:i+last] == h.needle[:last] {
+			return i
+		}
+	}
+	return -1
+}
+
+// primeRK is the base of the rolling hash, the one package strings uses.
+const primeRK = 16777619
+
+// rabinKarp compares a rolling hash of the window with the hash of the
+// needle and the bytes only when the hashes are equal.
+type rabinKarp struct {
+	needle    string
+	hash, pow uint32 // pow is primeRK^len(needle)
+}
+
+func newRabinKarp(needle string) *rabinKarp {
+	r := &rabinKarp{needle: needle, pow: 1}
+	for i := 0; i < len(needle); i++ {
+		r.hash = r.hash*primeRK + uint32(needle[i])
+	}
+	for i := 0; i < len(needle); i++ {
+		r.pow *= primeRK
+	}
+	return r
+}
+
+func (r *rabinKarp) Index(s string) int {
+	m := len(r.needle)
+	if m > len(s) {
+		return -1
+	}
+	var h uint32
+	for i := 0; i < m; i++ {
+		h = h*primeRK + uint32(s[i])
+	}
+	if h == r.hash && s[:m] == r.needle {
+		return 0
+	}
+	for i := m; i < len(s); {
+		h = h*primeRK + uint32(s[i]) - r.pow*uint32(s[i-m])
+		i++
+		if h == r.hash && s[i-m:i] == r.needle {
+			return i - m
+		}
+	}
+	return -1
+}
+
+// twoWay is the Two-Way searcher of Crochemore and Perrin: the needle is
+// split at a critical factorization, the right part is matched left to
+// right and the left part right to left. It runs in linear time with
+// constant extra space, so no haystack is a worst case.
+type twoWay struct {
+	needle   string
+	ell      int  // the left part is needle[:ell+1]
+	period   int  // shift after a full match
+	periodic bool // the left part repeats with period, remember matches
+}
+
+func newTwoWay(needle string) *twoWay {
+	i, p := maxSuffix(needle, false)
+	j, q := maxSuffix(needle, true)
+	t := &twoWay{needle: needle, ell: i, period: p}
+	if j > i {
+		t.ell, t.period = j, q
+	}
+	m := len(needle)
+	if t.period+t.ell+1 <= m && needle[:t.ell+1] == needle[t.period:t.period+t.ell+1] {
+		t.periodic = true
+	} else {
+		t.period = max(t.ell+1, m-t.ell-1) + 1
+	}
+	return t
+}
+
+// maxSuffix returns the index before the maximal suffix of x and its
+// period, for the byte order or, with reversed, its opposite.
+func maxSuffix(x string, reversed bool) (ms, p int) {
+	ms, j, k, p := -1, 0, 1, 1
+	for j+k < len(x) {
+		a, b := x[j+k], x[ms+k]
+		if reversed {
+			a, b = b, a
+		}
+		switch {
+		case a < b:
+			j += k
+			k = 1
+			p = j - ms
+		case a == b:
+			if k != p {
+				k++
+			} else {
+				j += p
+				k = 1
+			}
+		default:
+			ms = j
+			j = ms + 1
+			k, p = 1, 1
+		}
+	}
+	return ms, p
+}
+
+func (t *twoWay) Index(s string) int {
+	x, m := t.needle, len(t.needle)
+	if m == 0 {
+		return 0
+	}
+	if t.periodic {
+		memory := -1
+		for j := 0; j <= len(s)-m; {
+			i := max(t.ell, memory) + 1
+			for i < m && x[i] == s[i+j] {
+				i++
+			}
+			if i < m {
+				j += i - t.ell
+				memory = -1
+				continue
+			}
+			for i = t.ell; i > memory && x[i] == s[i+j]; i-- {
+			}
+			if i <= memory {
+				return j
+			}
+			j += t.period
+			memory = m - t.period - 1
+		}
+		return -1
+	}
+	for j := 0; j <= len(s)-m; {
+		i := t.ell + 1
+		for i < m && x[i] == s[i+j] {
+			i++
+		}
+		if i < m {
+			j += i - t.ell
+			continue
+		}
+		for i = t.ell; i >= 0 && x[i] == s[i+j]; i-- {
+		}
+		if i < 0 {
+			return j
+		}
+		j += t.period
+	}
+	return -1
+}
+
+// searchCase is a haystack and a needle with the searchers for the
+// needle prepared, as a log filter prepares them once for many lines.
+type searchCase struct {
+	haystack, needle string
+	hb, nb           []byte
+	re               *regexp.Regexp
+	horspool         *horspool
+	rabinKarp        *rabinKarp
+	twoWay           *twoWay
+}
+
+func newSearchCase(haystack, needle string) *searchCase {
+	return &searchCase{
+		haystack:  haystack,
+		needle:    needle,
+		hb:        []byte(haystack),
+		nb:        []byte(needle),
+		re:        regexp.MustCompile(regexp.QuoteMeta(needle)),
+		horspool:  newHorspool(needle),
+		rabinKarp: newRabinKarp(needle),
+		twoWay:    newTwoWay(needle),
+	}
+}
+
+// searchFamily reports whether the needle is found. The positions of the
+// hand-written searchers are checked by TestSearchIndex.
+var searchFamily = &benchutil.Family[*searchCase, bool]{
+	Reference: func(c *searchCase) bool {
+		for i := 0; i+len(c.needle) <= len(c.haystack); i++ {
+			if c.haystack[i:i+len(c.needle)] == c.needle {
+				return true
+			}
+		}
+		return false
+	},
+	Variants: []benchutil.Variant[*searchCase, bool]{
+		{Name: "Contains", Fn: func(c *searchCase) bool { return strings.Contains(c.haystack, c.needle) }},
+		{Name: "Index", Fn: func(c *searchCase) bool { return strings.Index(c.haystack, c.needle) >= 0 }},
+		{Name: "BytesIndex", Fn: func(c *searchCase) bool { return bytes.Index(c.hb, c.nb) >= 0 }},
+		{Name: "Regexp", Fn: func(c *searchCase) bool { return c.re.MatchString(c.haystack) }},
+		{Name: "Horspool", Fn: func(c *searchCase) bool { return c.horspool.Index(c.haystack) >= 0 }},
+		{Name: "RabinKarp", Fn: func(c *searchCase) bool { return c.rabinKarp.Index(c.haystack) >= 0 }},
+		{Name: "TwoWay", Fn: func(c *searchCase) bool { return c.twoWay.Index(c.haystack) >= 0 }},
+	},
+	Inputs: func(g *benchutil.Gen) []*searchCase {
+		in := []*searchCase{
+			newSearchCase("", ""), newSearchCase("a", ""), newSearchCase("", "a"),
+			newSearchCase("Lorem Ipsum", "em Ip"), newSearchCase("Lorem Ipsum", "Dolor"),
+			newSearchCase("aaaaaaaaab", "aaab"), newSearchCase("abababababc", "ababc"),
+		}
+		for range 200 {
+			in = append(in, newSearchCase(smallAlphabet(g, g.Int(0, 64)), smallAlphabet(g, g.Int(1, 8))))
+		}
+		return in
+	},
+}
+
+// smallAlphabet returns n bytes of "ab", where needles match often and
+// partly and the periodic case of Two-Way comes up.
+func smallAlphabet(g *benchutil.Gen, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = "ab"[g.Int(0, 2)]
+	}
+	return string(b)
+}
+
+func TestSearch(t *testing.T) {
+	searchFamily.Verify(t)
+}
+
+func TestSearchIndex(t *testing.T) {
+	g := benchutil.NewGen(benchutil.Seed)
+	for range 5000 {
+		s, needle := smallAlphabet(g, g.Int(0, 100)), smallAlphabet(g, g.Int(0, 10))
+		want := strings.Index(s, needle)
+		for name, got := range map[string]int{
+			"Horspool":  newHorspool(needle).Index(s),
+			"RabinKarp": newRabinKarp(needle).Index(s),
+			"TwoWay":    newTwoWay(needle).Index(s),
+		} {
+			if got != want {
+				t.Fatalf("%s: Index(%q, %q) = %d, want %d", name, s, needle, got, want)
+			}
+		}
+	}
+}
+
+// searchKinds place the needle in a haystack of n bytes:
+//
+//   - Best: at the start.
+//   - Worst: at the end, the whole haystack is scanned.
+//   - Random: anywhere.
+//   - Adversarial: a^(m-1)b at the end of a haystack of a, where every
+//     window matches all but the last byte.
+//
+// The text is random lowercase words, the needle is a random lowercase
+// word. Earlier chance matches are removed, so the needle is found
+// exactly where it is placed.
+var searchKinds = []struct {
+	name  string
+	build func(g *benchutil.Gen, n, m int) *searchCase
+}{
+	{"Best", func(g *benchutil.Gen, n, m int) *searchCase { return plant(g, n, m, 0) }},
+	{"Worst", func(g *benchutil.Gen, n, m int) *searchCase { return plant(g, n, m, n-m) }},
+	{"Random", func(g *benchutil.Gen, n, m int) *searchCase { return plant(g, n, m, g.Int(0, n-m+1)) }},
+	{"Adversarial", func(g *benchutil.Gen, n, m int) *searchCase {
+		needle := strings.Repeat("a", m-1) + "b"
+		return newSearchCase(strings.Repeat("a", n-m)+needle, needle)
+	}},
+}
+
+// plant returns a haystack of n bytes of words with a needle of m bytes
+// first found at offset p.
+func plant(g *benchutil.Gen, n, m, p int) *searchCase {
+	text := make([]byte, n)
+	for i := range text {
+		if g.Int(0, 6) == 0 {
+			text[i] = ' '
+		} else {
+			text[i] = byte('a' + g.Int(0, 26))
+		}
+	}
+	needle := make([]byte, m)
+	for i := range needle {
+		needle[i] = byte('a' + g.Int(0, 26))
+	}
+	copy(text[p:], needle)
+	// The needle has no space, a space at the start of a chance match
+	// removes it without creating another one.
+	for i := 0; ; {
+		j := i + bytes.Index(text[i:], needle)
+		if j == p {
+			break
+		}
+		text[j] = ' '
+		i = j + 1
+	}
+	return newSearchCase(string(text), string(needle))
+}
+
+// searchSizes are the haystacks, from a short log field to a log file.
+var searchSizes = []int{16, 256, 4 << 10, 64 << 10, 1 << 20, 16 << 20}
+
+// BenchmarkSearch searches a needle of 16 bytes in haystacks of every
+// size.
+func BenchmarkSearch(b *testing.B) {
+	for _, kind := range searchKinds {
+		b.Run(kind.name, func(b *testing.B) {
+			for _, v := range searchFamily.Variants {
+				b.Run(v.Name, func(b *testing.B) {
+					benchutil.Sizes(b, searchSizes, func(b *testing.B, n int) {
+						c := kind.build(benchutil.NewGen(benchutil.Seed), n, 16)
+						b.SetBytes(int64(n))
+						searchFamily.BenchmarkVariant(b, v.Name, c)
+					})
+				})
+			}
+		})
+	}
+}
+
+// needleSizes are the needles, from a single byte to a long stack trace
+// line.
+var needleSizes = []int{1, 4, 16, 64, 256}
+
+// BenchmarkSearchNeedle searches needles of every size in 64 KiB.
+func BenchmarkSearchNeedle(b *testing.B) {
+	const n = 64 << 10
+	for _, kind := range searchKinds {
+		b.Run(kind.name, func(b *testing.B) {
+			for _, v := range searchFamily.Variants {
+				b.Run(v.Name, func(b *testing.B) {
+					for _, m := range needleSizes {
+						b.Run(fmt.Sprintf("needle=%d", m), func(b *testing.B) {
+							c := kind.build(benchutil.NewGen(benchutil.Seed), n, m)
+							b.SetBytes(n)
+							searchFamily.BenchmarkVariant(b, v.Name, c)
+						})
+					}
+				})
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 //go:embed example.txt
@@ -13,7 +15,7 @@ var embeddedFile []byte
 func BenchmarkEmbed(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		// Access the embedded file
-		_ = embeddedFile
+		benchutil.BytesSink.Store(embeddedFile)
 	}
 }
 
@@ -24,7 +26,7 @@ func BenchmarkReadFile(b *testing.B) {
 		if err != nil {
 			b.Fatalf("failed to read file: %v", err)
 		}
-		_ = data
+		benchutil.BytesSink.Store(data)
 	}
 }
 
@@ -35,6 +37,6 @@ func BenchmarkIoutilReadFile(b *testing.B) {
 		if err != nil {
 			b.Fatalf("failed to read file: %v", err)
 		}
-		_ = data
+		benchutil.BytesSink.Store(data)
 	}
 }
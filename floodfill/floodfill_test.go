@@ -3,6 +3,8 @@ package main
 
 import (
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 // Rekursive Implementierung
@@ -118,50 +120,66 @@ var complexImage = [][]int{
 	{0, 1, 1, 0, 1, 1},
 }
 
+// fill ist ein Aufruf einer Flood-Fill-Implementierung
+type fill struct {
+	image         [][]int
+	sr, sc, color int
+}
+
+// floodFillVariant kopiert das Bild vor jedem Aufruf, da alle
+// Implementierungen es direkt verändern
+func floodFillVariant(name string, fn func([][]int, int, int, int) [][]int) benchutil.Variant[fill, [][]int] {
+	return benchutil.Variant[fill, [][]int]{
+		Name: name,
+		Fn: func(f fill) [][]int {
+			return fn(benchutil.CloneGrid(f.image), f.sr, f.sc, f.color)
+		},
+	}
+}
+
+// Alle Implementierungen müssen dasselbe Bild liefern wie die rekursive
+var floodFillFamily = &benchutil.Family[fill, [][]int]{
+	Reference: floodFillVariant("Recursive", floodFillRecursive).Fn,
+	Variants: []benchutil.Variant[fill, [][]int]{
+		floodFillVariant("Recursive", floodFillRecursive),
+		floodFillVariant("DFS", floodFillDFS),
+		floodFillVariant("BFS", floodFillBFS),
+		floodFillVariant("Stack4Way", floodFillStack4Way),
+	},
+	Inputs: func(g *benchutil.Gen) []fill {
+		fills := []fill{{complexImage, 1, 1, 2}, {complexImage, 0, 0, 0}}
+		for range 200 {
+			rows, cols := g.Int(1, 13), g.Int(1, 13)
+			image := make([][]int, rows)
+			for r := range image {
+				image[r] = g.Ints(cols, 0, 3)
+			}
+			fills = append(fills, fill{image, g.Int(0, rows), g.Int(0, cols), g.Int(0, 4)})
+		}
+		return fills
+	},
+}
+
+func TestFloodFill(t *testing.T) {
+	floodFillFamily.Verify(t)
+}
+
 // Benchmark für die rekursive Implementierung
 func BenchmarkFloodFillRecursive(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		image := make([][]int, len(complexImage))
-		for j := range complexImage {
-			image[j] = make([]int, len(complexImage[j]))
-			copy(image[j], complexImage[j])
-		}
-		floodFillRecursive(image, 1, 1, 2)
-	}
+	floodFillFamily.BenchmarkVariant(b, "Recursive", fill{complexImage, 1, 1, 2})
 }
 
 // Benchmark für die iterative Implementierung mit Stack (DFS)
 func BenchmarkFloodFillDFS(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		image := make([][]int, len(complexImage))
-		for j := range complexImage {
-			image[j] = make([]int, len(complexImage[j]))
-			copy(image[j], complexImage[j])
-		}
-		floodFillDFS(image, 1, 1, 2)
-	}
+	floodFillFamily.BenchmarkVariant(b, "DFS", fill{complexImage, 1, 1, 2})
 }
 
 // Benchmark für die iterative Implementierung mit Queue (BFS)
 func BenchmarkFloodFillBFS(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		image := make([][]int, len(complexImage))
-		for j := range complexImage {
-			image[j] = make([]int, len(complexImage[j]))
-			copy(image[j], complexImage[j])
-		}
-		floodFillBFS(image, 1, 1, 2)
-	}
+	floodFillFamily.BenchmarkVariant(b, "BFS", fill{complexImage, 1, 1, 2})
 }
 
 // Benchmark für die iterative Implementierung mit Stack (4-Wege-Verbindung)
 func BenchmarkFloodFillStack4Way(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		image := make([][]int, len(complexImage))
-		for j := range complexImage {
-			image[j] = make([]int, len(complexImage[j]))
-			copy(image[j], complexImage[j])
-		}
-		floodFillStack4Way(image, 1, 1, 2)
-	}
+	floodFillFamily.BenchmarkVariant(b, "Stack4Way", fill{complexImage, 1, 1, 2})
 }
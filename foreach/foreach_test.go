@@ -3,6 +3,8 @@ package foreach
 
 import (
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 var amap map[int]string
@@ -27,27 +29,35 @@ func init() {
 }
 
 func forMap() {
+	n := 0
 	for i := 0; i < len(amap); i++ {
-		_ = amap[i]
+		n += len(amap[i])
 	}
+	benchutil.IntSink.Store(n)
 }
 
 func rangeMap() {
+	n := 0
 	for _, v := range amap {
-		_ = v
+		n += len(v)
 	}
+	benchutil.IntSink.Store(n)
 }
 
 func rangeSlice() {
+	n := 0
 	for _, v := range aslice {
-		_ = v
+		n += len(v)
 	}
+	benchutil.IntSink.Store(n)
 }
 
 func rangeSliceKey() {
+	n := 0
 	for k := range aslice {
-		_ = aslice[k]
+		n += len(aslice[k])
 	}
+	benchutil.IntSink.Store(n)
 }
 
 func BenchmarkForMap(b *testing.B) {
@@ -13,9 +13,12 @@ import (
 	"hash/crc32"
 	"hash/crc64"
 	"hash/fnv"
-	"math/rand"
+	"hash/maphash"
 	"testing"
 
+	"github.com/SimonWaldherr/golang-benchmarks/hash/siphash"
+	"github.com/SimonWaldherr/golang-benchmarks/hash/xxhash"
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 	"github.com/jzelinskie/whirlpool"
 	"github.com/reusee/mmh3"
 	"github.com/zeebo/blake3"
@@ -27,24 +30,25 @@ import (
 )
 
 func benchmarkHashAlgo(b *testing.B, h hash.Hash) {
-	data := make([]byte, 2048)
-	rand.Read(data)
+	data := benchutil.Bytes(2048)
 
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
 		h.Reset()
 		h.Write(data)
-		_ = h.Sum(nil)
+		benchutil.BytesSink.Store(h.Sum(nil))
 	}
 }
 
+// benchmarkBCryptHashAlgo hashes a password, bcrypt rejects inputs
+// longer than 72 bytes without doing any work.
 func benchmarkBCryptHashAlgo(b *testing.B, cost int) {
-	data := make([]byte, 2048)
-	rand.Read(data)
-
-	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
-		bcrypt.GenerateFromPassword(data, cost)
+		h, err := bcrypt.GenerateFromPassword(password, cost)
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchutil.BytesSink.Store(h)
 	}
 }
 
@@ -138,6 +142,10 @@ func BenchmarkFnv128a(b *testing.B) {
 	benchmarkHashAlgo(b, fnv.New128a())
 }
 
+func BenchmarkMapHash(b *testing.B) {
+	benchmarkHashAlgo(b, new(maphash.Hash))
+}
+
 func BenchmarkMD4(b *testing.B) {
 	benchmarkHashAlgo(b, md4.New())
 }
@@ -178,14 +186,85 @@ func BenchmarkRIPEMD160(b *testing.B) {
 	benchmarkHashAlgo(b, ripemd160.New())
 }
 
+func BenchmarkSipHash24(b *testing.B) {
+	benchmarkHashAlgo(b, siphash.New(sipKey))
+}
+
 func BenchmarkWhirlpool(b *testing.B) {
 	benchmarkHashAlgo(b, whirlpool.New())
 }
 
+func BenchmarkXXH64(b *testing.B) {
+	benchmarkHashAlgo(b, xxhash.New())
+}
+
+// hashAlgos are the algorithms of the size sweep. bcrypt is left out, it
+// is a password hash with a fixed cost and at most 72 bytes of input.
+var hashAlgos = []struct {
+	name string
+	new  func() hash.Hash
+}{
+	{"Adler32", func() hash.Hash { return adler32.New() }},
+	{"Blake2b256", func() hash.Hash { return mustHash(blake2b.New256(nil)) }},
+	{"Blake2b512", func() hash.Hash { return mustHash(blake2b.New512(nil)) }},
+	{"Blake3256", func() hash.Hash { return blake3.New() }},
+	{"MMH3", func() hash.Hash { return mmh3.New128() }},
+	{"CRC32", func() hash.Hash { return crc32.NewIEEE() }},
+	{"CRC64ISO", func() hash.Hash { return crc64.New(crc64.MakeTable(crc64.ISO)) }},
+	{"CRC64ECMA", func() hash.Hash { return crc64.New(crc64.MakeTable(crc64.ECMA)) }},
+	{"Fnv32", func() hash.Hash { return fnv.New32() }},
+	{"Fnv32a", func() hash.Hash { return fnv.New32a() }},
+	{"Fnv64", func() hash.Hash { return fnv.New64() }},
+	{"Fnv64a", func() hash.Hash { return fnv.New64a() }},
+	{"Fnv128", func() hash.Hash { return fnv.New128() }},
+	{"Fnv128a", func() hash.Hash { return fnv.New128a() }},
+	{"MapHash", func() hash.Hash { return new(maphash.Hash) }},
+	{"MD4", md4.New},
+	{"MD5", md5.New},
+	{"SHA1", sha1.New},
+	{"SHA224", sha256.New224},
+	{"SHA256", sha256.New},
+	{"SHA384", sha512.New384},
+	{"SHA512", sha512.New},
+	{"SHA3256", func() hash.Hash { return sha3.New256() }},
+	{"SHA3512", func() hash.Hash { return sha3.New512() }},
+	{"RIPEMD160", ripemd160.New},
+	{"SipHash24", func() hash.Hash { return siphash.New(sipKey) }},
+	{"Whirlpool", whirlpool.New},
+	{"XXH64", func() hash.Hash { return xxhash.New() }},
+}
+
+func mustHash(h hash.Hash, err error) hash.Hash {
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+// hashSizes go from short map keys, where the per call overhead
+// dominates, to large blobs, where only the throughput counts.
+var hashSizes = []int{8, 64, 1 << 10, 64 << 10, 8 << 20}
+
+func BenchmarkHashSizes(b *testing.B) {
+	data := benchutil.Bytes(hashSizes[len(hashSizes)-1])
+	for _, algo := range hashAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			benchutil.Sizes(b, hashSizes, func(b *testing.B, n int) {
+				h := algo.new()
+				b.SetBytes(int64(n))
+				for i := 0; i < b.N; i++ {
+					h.Reset()
+					h.Write(data[:n])
+					benchutil.BytesSink.Store(h.Sum(nil))
+				}
+			})
+		})
+	}
+}
+
 func BenchmarkSHA256Parallel(b *testing.B) {
 	b.RunParallel(func(pb *testing.PB) {
-		data := make([]byte, 2048)
-		rand.Read(data)
+		data := benchutil.Bytes(2048)
 		for pb.Next() {
 			h := sha256.New()
 			h.Write(data)
@@ -0,0 +1,187 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// password and salt are the inputs of the password hashes: a typical
+// user password and the 16 byte salt all of them recommend.
+var (
+	password = []byte("correct horse battery staple")
+	salt     = benchutil.Bytes(16)
+)
+
+// bcryptMemory is the Blowfish state bcrypt works on, four S-boxes of
+// 1 KiB and the 72 byte P-array, independent of the cost.
+const bcryptMemory = 4*1024 + 18*4
+
+// reportMemory reports the working memory a cost setting needs per
+// hash. Next to ns/op it tells how many logins fit into a server at once.
+// PBKDF2 and HKDF work in the constant state of their HMAC and do not
+// report it.
+func reportMemory(b *testing.B, bytes int) {
+	b.ReportMetric(float64(bytes), "memory-B")
+}
+
+// BenchmarkKDFBcrypt fills in the costs between the BenchmarkBCryptCost
+// benchmarks of hash_test.go, which time 4, 10 and 16.
+func BenchmarkKDFBcrypt(b *testing.B) {
+	for _, cost := range []int{6, 8, 12} {
+		b.Run(fmt.Sprintf("cost=%d", cost), func(b *testing.B) {
+			reportMemory(b, bcryptMemory)
+			for i := 0; i < b.N; i++ {
+				h, err := bcrypt.GenerateFromPassword(password, cost)
+				if err != nil {
+					b.Fatal(err)
+				}
+				benchutil.BytesSink.Store(h)
+			}
+		})
+	}
+}
+
+// BenchmarkKDFScrypt sweeps the CPU/memory cost N with r=8 and p=1, from
+// far too weak to the 2^17 recommended for file encryption. 2^15 is the
+// interactive login setting of the scrypt paper.
+func BenchmarkKDFScrypt(b *testing.B) {
+	const r, p = 8, 1
+	for _, n := range []int{1 << 10, 1 << 12, 1 << 14, 1 << 15, 1 << 17} {
+		b.Run(fmt.Sprintf("N=%d", n), func(b *testing.B) {
+			reportMemory(b, 128*r*n+128*r*p)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				k, err := scrypt.Key(password, salt, n, r, p, 32)
+				if err != nil {
+					b.Fatal(err)
+				}
+				benchutil.BytesSink.Store(k)
+			}
+		})
+	}
+}
+
+// BenchmarkKDFArgon2id sweeps the memory of argon2id at the two passes
+// and single thread of the OWASP baseline of 19 MiB.
+func BenchmarkKDFArgon2id(b *testing.B) {
+	const time, threads = 2, 1
+	benchutil.Sizes(b, []int{8 << 20, 19 << 20, 64 << 20, 128 << 20}, func(b *testing.B, n int) {
+		reportMemory(b, n)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchutil.BytesSink.Store(argon2.IDKey(password, salt, time, uint32(n>>10), threads, 32))
+		}
+	})
+}
+
+// BenchmarkKDFArgon2idTime sweeps the passes of argon2id at 19 MiB, the
+// other knob to trade latency for cost to an attacker.
+func BenchmarkKDFArgon2idTime(b *testing.B) {
+	const memory, threads = 19 << 10, 1
+	for _, time := range []uint32{1, 2, 3, 4} {
+		b.Run(fmt.Sprintf("t=%d", time), func(b *testing.B) {
+			reportMemory(b, memory<<10)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				benchutil.BytesSink.Store(argon2.IDKey(password, salt, time, memory, threads, 32))
+			}
+		})
+	}
+}
+
+// BenchmarkKDFPBKDF2 sweeps the iterations up to the OWASP settings of
+// 600,000 for HMAC-SHA256 and 210,000 for HMAC-SHA512.
+func BenchmarkKDFPBKDF2(b *testing.B) {
+	for _, h := range []struct {
+		name string
+		new  func() hash.Hash
+	}{
+		{"SHA256", sha256.New},
+		{"SHA512", sha512.New},
+	} {
+		b.Run(h.name, func(b *testing.B) {
+			for _, iter := range []int{1000, 10000, 100000, 210000, 600000} {
+				b.Run(fmt.Sprintf("iter=%d", iter), func(b *testing.B) {
+					for i := 0; i < b.N; i++ {
+						benchutil.BytesSink.Store(pbkdf2.Key(password, salt, iter, 32, h.new))
+					}
+				})
+			}
+		})
+	}
+}
+
+// BenchmarkKDFHKDF derives keys of growing length from a secret that is
+// already strong, such as a key exchange result. HKDF has no cost knob,
+// it is here as the floor of the other KDFs.
+func BenchmarkKDFHKDF(b *testing.B) {
+	secret := benchutil.Bytes(32)
+	info := []byte("golang-benchmarks hkdf")
+	benchutil.Sizes(b, []int{32, 64, 1 << 10, 255 * sha256.Size}, func(b *testing.B, n int) {
+		b.ReportAllocs()
+		key := make([]byte, n)
+		for i := 0; i < b.N; i++ {
+			if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), key); err != nil {
+				b.Fatal(err)
+			}
+		}
+		benchutil.BytesSink.Store(key)
+	})
+}
+
+// TestKDFs checks the KDFs against the test vectors of their RFCs, so a
+// benchmark cannot silently time a failing call.
+func TestKDFs(t *testing.T) {
+	unhex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	check := func(name string, got []byte, want string) {
+		t.Helper()
+		if hex.EncodeToString(got) != want {
+			t.Errorf("%s = %x, want %s", name, got, want)
+		}
+	}
+
+	// RFC 7914, section 12.
+	k, err := scrypt.Key([]byte("password"), []byte("NaCl"), 1024, 8, 16, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("scrypt", k, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640")
+
+	// RFC 7914, section 11.
+	check("PBKDF2-HMAC-SHA256", pbkdf2.Key([]byte("passwd"), []byte("salt"), 1, 64, sha256.New),
+		"55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783")
+
+	// RFC 5869, test case 1.
+	okm := make([]byte, 42)
+	r := hkdf.New(sha256.New, unhex("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b"), unhex("000102030405060708090a0b0c"), unhex("f0f1f2f3f4f5f6f7f8f9"))
+	if _, err := io.ReadFull(r, okm); err != nil {
+		t.Fatal(err)
+	}
+	check("HKDF-SHA256", okm, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865")
+
+	h, err := bcrypt.GenerateFromPassword(password, bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bcrypt.CompareHashAndPassword(h, password); err != nil {
+		t.Errorf("bcrypt does not accept its own hash: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package hash
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+	"github.com/zeebo/blake3"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/poly1305"
+)
+
+// macKey is 32 bytes, the key size of keyed BLAKE3 and Poly1305.
+var macKey = benchutil.Bytes(32)
+
+// poly1305MAC adapts the one-time authenticator to hash.Hash. Reset
+// starts over with the same key, which keeps the benchmark comparable to
+// the other MACs but is only fit for timing: Poly1305 must never see two
+// messages under one key, real callers derive a fresh key per message, as
+// ChaCha20-Poly1305 does from its nonce.
+type poly1305MAC struct {
+	key [32]byte
+	*poly1305.MAC
+}
+
+func newPoly1305(key []byte) hash.Hash {
+	p := &poly1305MAC{}
+	copy(p.key[:], key)
+	p.Reset()
+	return p
+}
+
+func (p *poly1305MAC) Reset()         { p.MAC = poly1305.New(&p.key) }
+func (p *poly1305MAC) BlockSize() int { return 16 }
+
+// macAlgos are the message authentication codes, each keyed once and
+// reset between messages the way a server reuses its MAC.
+var macAlgos = []struct {
+	name string
+	new  func(key []byte) hash.Hash
+}{
+	{"HMACSHA256", func(key []byte) hash.Hash { return hmac.New(sha256.New, key) }},
+	{"HMACSHA512", func(key []byte) hash.Hash { return hmac.New(sha512.New, key) }},
+	{"Blake2b256Keyed", func(key []byte) hash.Hash { return mustHash(blake2b.New256(key)) }},
+	{"Blake3Keyed", func(key []byte) hash.Hash { return mustHash(blake3.NewKeyed(key)) }},
+	{"Poly1305", newPoly1305},
+}
+
+// macSizes go from a token or cookie to a request body.
+var macSizes = []int{16, 64, 1 << 10, 64 << 10}
+
+func BenchmarkMAC(b *testing.B) {
+	data := benchutil.Bytes(macSizes[len(macSizes)-1])
+	for _, algo := range macAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			benchutil.Sizes(b, macSizes, func(b *testing.B, n int) {
+				h := algo.new(macKey)
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					h.Reset()
+					h.Write(data[:n])
+					benchutil.BytesSink.Store(h.Sum(nil))
+				}
+			})
+		})
+	}
+}
+
+func TestMACs(t *testing.T) {
+	// RFC 4231, test case 2.
+	h := hmac.New(sha256.New, []byte("Jefe"))
+	h.Write([]byte("what do ya want for nothing?"))
+	if got, want := hex.EncodeToString(h.Sum(nil)), "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"; got != want {
+		t.Errorf("HMAC-SHA256 = %s, want %s", got, want)
+	}
+
+	msg := benchutil.Bytes(1 << 10)
+	otherKey := bytes.Clone(macKey)
+	otherKey[0] ^= 1
+	for _, algo := range macAlgos {
+		h := algo.new(macKey)
+		h.Write([]byte("previous message"))
+		h.Reset()
+		h.Write(msg)
+		tag := h.Sum(nil)
+
+		fresh := algo.new(macKey)
+		fresh.Write(msg)
+		if !bytes.Equal(tag, fresh.Sum(nil)) {
+			t.Errorf("%s: tag after Reset differs from a fresh MAC", algo.name)
+		}
+		other := algo.new(otherKey)
+		other.Write(msg)
+		if bytes.Equal(tag, other.Sum(nil)) {
+			t.Errorf("%s: tag does not depend on the key", algo.name)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package hash
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"hash/maphash"
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+	"unsafe"
+
+	"github.com/SimonWaldherr/golang-benchmarks/hash/siphash"
+	"github.com/SimonWaldherr/golang-benchmarks/hash/wyhash"
+	"github.com/SimonWaldherr/golang-benchmarks/hash/xxhash"
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// sipKey is the key of the SipHash benchmarks, the key of the test
+// vectors in the paper.
+var sipKey = []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// mapSeed seeds the maphash variants. A fixed seed per process is what a
+// hash table does, a new one per key would not be comparable.
+var mapSeed = maphash.MakeSeed()
+
+// str views b as a string without copying, so the string based maphash
+// functions hash the same memory as the others.
+func str(b []byte) string {
+	return unsafe.String(unsafe.SliceData(b), len(b))
+}
+
+// mapHashes are the hashes used for hash tables and sharding, as one-shot
+// functions of the key. FNV-1a is the simple baseline many hand-written
+// tables start with.
+var mapHashes = []struct {
+	name string
+	sum  func(key []byte) uint64
+}{
+	{"MapHash", func(key []byte) uint64 {
+		var h maphash.Hash
+		h.SetSeed(mapSeed)
+		h.Write(key)
+		return h.Sum64()
+	}},
+	{"MapHashBytes", func(key []byte) uint64 { return maphash.Bytes(mapSeed, key) }},
+	{"MapHashString", func(key []byte) uint64 { return maphash.String(mapSeed, str(key)) }},
+	{"MapHashComparable", func(key []byte) uint64 { return maphash.Comparable(mapSeed, str(key)) }},
+	{"XXH64", xxhash.Sum64},
+	{"XXH3", xxhash.Sum3},
+	{"WyHash", func(key []byte) uint64 { return wyhash.Hash(key, 0) }},
+	{"SipHash24", func(key []byte) uint64 { return siphash.Sum64(0x0706050403020100, 0x0f0e0d0c0b0a0908, key) }},
+	{"Fnv64a", func(key []byte) uint64 {
+		h := fnv.New64a()
+		h.Write(key)
+		return h.Sum64()
+	}},
+}
+
+// mapKeySizes span the keys of hash tables: integers, UUIDs, short
+// strings and the occasional long one.
+var mapKeySizes = []int{4, 8, 16, 32, 64, 128, 1 << 10, 64 << 10}
+
+func BenchmarkMapHashSizes(b *testing.B) {
+	data := benchutil.Bytes(mapKeySizes[len(mapKeySizes)-1])
+	for _, mh := range mapHashes {
+		b.Run(mh.name, func(b *testing.B) {
+			benchutil.Sizes(b, mapKeySizes, func(b *testing.B, n int) {
+				key := data[:n]
+				b.SetBytes(int64(n))
+				var h uint64
+				for i := 0; i < b.N; i++ {
+					h += mh.sum(key)
+				}
+				benchutil.Int64Sink.Store(int64(h))
+			})
+		})
+	}
+}
+
+type compositeKey struct {
+	id   int64
+	name string
+}
+
+// BenchmarkMapHashComparable hashes typed keys with maphash.Comparable,
+// which hashes the memory of the value instead of a byte slice.
+func BenchmarkMapHashComparable(b *testing.B) {
+	b.Run("Int64", func(b *testing.B) {
+		benchmarkComparable(b, int64(0x5eed))
+	})
+	b.Run("String", func(b *testing.B) {
+		benchmarkComparable(b, "user:12345")
+	})
+	b.Run("Array16", func(b *testing.B) {
+		benchmarkComparable(b, [16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	})
+	b.Run("Struct", func(b *testing.B) {
+		benchmarkComparable(b, compositeKey{id: 12345, name: "user"})
+	})
+}
+
+func benchmarkComparable[T comparable](b *testing.B, key T) {
+	var h uint64
+	for i := 0; i < b.N; i++ {
+		h += maphash.Comparable(mapSeed, key)
+	}
+	benchutil.Int64Sink.Store(int64(h))
+}
+
+// qualityKeys is the number of keys of the quality report, the low
+// qualityBits of their hashes are checked for collisions. A uniform hash
+// collides about 128 times on 2^16 keys in 2^24 values. The avalanche
+// test flips bits in the first avalancheKeys keys, enough that sampling
+// noise stays below one percent.
+const (
+	qualityKeys   = 1 << 16
+	qualityBits   = 24
+	qualityBins   = 1 << 10
+	avalancheKeys = 1 << 12
+)
+
+// qualityKeySets are structured keys of the kind that breaks weak hashes:
+// short sequential strings and counters that differ in a few low bits.
+var qualityKeySets = []struct {
+	name string
+	keys func() [][]byte
+}{
+	{"Words", func() [][]byte {
+		keys := make([][]byte, qualityKeys)
+		for i := range keys {
+			keys[i] = strconv.AppendInt([]byte("key-"), int64(i), 10)
+		}
+		return keys
+	}},
+	{"Counters", func() [][]byte {
+		keys := make([][]byte, qualityKeys)
+		for i := range keys {
+			keys[i] = binary.LittleEndian.AppendUint64(nil, uint64(i))
+		}
+		return keys
+	}},
+}
+
+// BenchmarkMapHashQuality hashes a whole key set per op and reports next
+// to the speed how well the hashes spread:
+//
+//   - collisions/expected: collisions in the low 24 bits relative to a
+//     uniform random function, 1 is ideal.
+//   - chi2/df: chi-squared statistic of the low 10 bits as bucket index
+//     divided by its degrees of freedom, around 1 for a uniform hash.
+//   - avalanche-bias: the largest deviation from 50% of any output bit
+//     flipping when a single input bit flips, 0 is ideal.
+func BenchmarkMapHashQuality(b *testing.B) {
+	for _, set := range qualityKeySets {
+		keys := set.keys()
+		b.Run(set.name, func(b *testing.B) {
+			for _, mh := range mapHashes {
+				b.Run(mh.name, func(b *testing.B) {
+					sums := make([]uint64, len(keys))
+					for i := 0; i < b.N; i++ {
+						for k, key := range keys {
+							sums[k] = mh.sum(key)
+						}
+					}
+					b.StopTimer()
+					b.ReportMetric(float64(collisions(sums, qualityBits))/expectedCollisions(len(sums), qualityBits), "collisions/expected")
+					b.ReportMetric(chiSquared(sums, qualityBins)/(qualityBins-1), "chi2/df")
+					b.ReportMetric(avalancheBias(mh.sum, keys[:avalancheKeys]), "avalanche-bias")
+				})
+			}
+		})
+	}
+}
+
+// collisions counts the hashes whose low bits equal those of an earlier
+// hash.
+func collisions(sums []uint64, bits int) int {
+	low := make([]uint64, len(sums))
+	for i, s := range sums {
+		low[i] = s & (1<<bits - 1)
+	}
+	slices.Sort(low)
+	n := 0
+	for i := 1; i < len(low); i++ {
+		if low[i] == low[i-1] {
+			n++
+		}
+	}
+	return n
+}
+
+// expectedCollisions is the mean of collisions for n uniform random
+// values of the given width.
+func expectedCollisions(n, bits int) float64 {
+	m := math.Ldexp(1, bits)
+	return float64(n) - m*(1-math.Pow(1-1/m, float64(n)))
+}
+
+// chiSquared tests the low bits of sums as bucket index against a uniform
+// distribution over bins buckets.
+func chiSquared(sums []uint64, bins int) float64 {
+	counts := make([]int, bins)
+	for _, s := range sums {
+		counts[s&uint64(bins-1)]++
+	}
+	want := float64(len(sums)) / float64(bins)
+	var chi2 float64
+	for _, c := range counts {
+		d := float64(c) - want
+		chi2 += d * d / want
+	}
+	return chi2
+}
+
+// avalancheBias flips each of the first 64 bits of every key and counts
+// for each output bit how often it changes. It returns the largest
+// deviation from one half over all input and output bit pairs.
+func avalancheBias(sum func([]byte) uint64, keys [][]byte) float64 {
+	var worst float64
+	for bit := 0; bit < 8*8; bit++ {
+		var flips [64]int
+		n := 0
+		for _, key := range keys {
+			if bit >= 8*len(key) {
+				continue
+			}
+			flipped := slices.Clone(key)
+			flipped[bit/8] ^= 1 << (bit % 8)
+			diff := sum(key) ^ sum(flipped)
+			for out := range flips {
+				flips[out] += int(diff >> out & 1)
+			}
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		for _, f := range flips {
+			worst = math.Max(worst, math.Abs(float64(f)/float64(n)-0.5))
+		}
+	}
+	return worst
+}
+
+// TestMapHashes checks that the map hashes agree with their reference:
+// the streaming maphash.Hash with the one-shot functions and the
+// sub-package implementations with their streaming counterparts.
+func TestMapHashes(t *testing.T) {
+	data := benchutil.Bytes(1 << 10)
+	byName := make(map[string]func([]byte) uint64)
+	for _, mh := range mapHashes {
+		byName[mh.name] = mh.sum
+	}
+	sip := siphash.New(sipKey)
+	for n := 0; n <= len(data); n = n*2 + 1 {
+		key := data[:n]
+		want := byName["MapHash"](key)
+		for _, name := range []string{"MapHashBytes", "MapHashString"} {
+			if got := byName[name](key); got != want {
+				t.Errorf("%s(%d bytes) = %#x, maphash.Hash returns %#x", name, n, got, want)
+			}
+		}
+		d := xxhash.New()
+		d.Write(key)
+		if got, want := byName["XXH64"](key), d.Sum64(); got != want {
+			t.Errorf("XXH64(%d bytes) = %#x, Digest returns %#x", n, got, want)
+		}
+		sip.Reset()
+		sip.Write(key)
+		if got, want := byName["SipHash24"](key), sip.Sum64(); got != want {
+			t.Errorf("SipHash24(%d bytes) = %#x, Digest returns %#x", n, got, want)
+		}
+	}
+}
+
+// TestQualityReport checks the statistics on a hash that is known to be
+// bad: the identity on counters has no avalanche at all.
+func TestQualityReport(t *testing.T) {
+	identity := func(key []byte) uint64 { return binary.LittleEndian.Uint64(key) }
+	keys := qualityKeySets[1].keys()
+	sums := make([]uint64, len(keys))
+	for i, key := range keys {
+		sums[i] = identity(key)
+	}
+	if n := collisions(sums, qualityBits); n != 0 {
+		t.Errorf("collisions of distinct counters = %d, want 0", n)
+	}
+	if chi2 := chiSquared(sums, qualityBins); chi2 != 0 {
+		t.Errorf("chi2 of counters = %v, want 0 for perfectly even buckets", chi2)
+	}
+	if bias := avalancheBias(identity, keys[:avalancheKeys]); bias != 0.5 {
+		t.Errorf("avalanche bias of the identity = %v, want 0.5", bias)
+	}
+	if e := expectedCollisions(qualityKeys, qualityBits); math.Abs(e-128) > 1 {
+		t.Errorf("expected collisions = %v, want about 128", e)
+	}
+}
@@ -0,0 +1,167 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"hash"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+	"github.com/zeebo/blake3"
+)
+
+// The benchmarks of this file are meant to be run at several GOMAXPROCS,
+// go test -cpu 1,2,4,8 or benchrun -cpu 1,2,4,8, which turns them into a
+// scaling table with the throughput per core and the parallel efficiency.
+
+// parallelSize is the message every goroutine hashes per op, a request
+// body or a chunk of a content addressed store.
+const parallelSize = 16 << 10
+
+// BenchmarkHashParallel hashes independent messages on every core, each
+// goroutine with a hasher of its own. Without shared state every
+// algorithm should scale with the cores until memory bandwidth runs out.
+func BenchmarkHashParallel(b *testing.B) {
+	data := benchutil.Bytes(parallelSize)
+	for _, algo := range hashAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			b.SetBytes(parallelSize)
+			b.RunParallel(func(pb *testing.PB) {
+				h := algo.new()
+				sum := make([]byte, 0, h.Size())
+				for pb.Next() {
+					h.Reset()
+					h.Write(data)
+					sum = h.Sum(sum[:0])
+				}
+				if len(sum) != 0 && len(sum) != h.Size() {
+					b.Errorf("sum of %d bytes, want %d", len(sum), h.Size())
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkHashSharedState compares ways to give goroutines a hasher:
+// one per goroutine, a sync.Pool, and a single hasher behind a mutex.
+// The mutex serializes all goroutines, it is the variant the scaling
+// table is expected to flag.
+func BenchmarkHashSharedState(b *testing.B) {
+	data := benchutil.Bytes(parallelSize)
+	hashOnce := func(h hash.Hash, sum []byte) []byte {
+		h.Reset()
+		h.Write(data)
+		return h.Sum(sum[:0])
+	}
+
+	b.Run("PerGoroutine", func(b *testing.B) {
+		b.SetBytes(parallelSize)
+		b.RunParallel(func(pb *testing.PB) {
+			h, sum := sha256.New(), make([]byte, 0, sha256.Size)
+			for pb.Next() {
+				sum = hashOnce(h, sum)
+			}
+		})
+	})
+	b.Run("Pool", func(b *testing.B) {
+		pool := sync.Pool{New: func() any { return sha256.New() }}
+		b.SetBytes(parallelSize)
+		b.RunParallel(func(pb *testing.PB) {
+			sum := make([]byte, 0, sha256.Size)
+			for pb.Next() {
+				h := pool.Get().(hash.Hash)
+				sum = hashOnce(h, sum)
+				pool.Put(h)
+			}
+		})
+	})
+	b.Run("Mutex", func(b *testing.B) {
+		var mu sync.Mutex
+		h := sha256.New()
+		b.SetBytes(parallelSize)
+		b.RunParallel(func(pb *testing.PB) {
+			sum := make([]byte, 0, sha256.Size)
+			for pb.Next() {
+				mu.Lock()
+				sum = hashOnce(h, sum)
+				mu.Unlock()
+			}
+		})
+	})
+}
+
+// blake3Size is large enough for BLAKE3 to fill all SIMD lanes with
+// chunks of its tree.
+const blake3Size = 1 << 20
+
+// BenchmarkBlake3Parallelism compares the parallelism of the BLAKE3 tree
+// with naive parallelism over goroutines. zeebo/blake3 hashes up to eight
+// (AVX-512: sixteen) chunks of the tree at once in SIMD lanes of a single
+// goroutine, so Tree does not get faster with more procs:
+//
+//   - Tree: one hasher on one message, the internal parallelism only.
+//   - SplitJoin: one message cut into GOMAXPROCS pieces hashed on their
+//     own goroutines, then the digests hashed together. This is not
+//     BLAKE3 of the message, it is what one would build without a tree
+//     hash, with a goroutine start and join per message.
+//   - PerGoroutine: independent messages on every core, each goroutine
+//     with its own hasher.
+func BenchmarkBlake3Parallelism(b *testing.B) {
+	data := benchutil.Bytes(blake3Size)
+
+	b.Run("Tree", func(b *testing.B) {
+		b.SetBytes(blake3Size)
+		for i := 0; i < b.N; i++ {
+			sumSink.Store(blake3.Sum256(data))
+		}
+	})
+	b.Run("SplitJoin", func(b *testing.B) {
+		b.SetBytes(blake3Size)
+		for i := 0; i < b.N; i++ {
+			sumSink.Store(splitJoinBlake3(data, runtime.GOMAXPROCS(0)))
+		}
+	})
+	b.Run("PerGoroutine", func(b *testing.B) {
+		b.SetBytes(blake3Size)
+		b.RunParallel(func(pb *testing.PB) {
+			h := blake3.New()
+			sum := make([]byte, 0, 32)
+			for pb.Next() {
+				h.Reset()
+				h.Write(data)
+				sum = h.Sum(sum[:0])
+			}
+		})
+	})
+}
+
+var sumSink benchutil.Sink[[32]byte]
+
+// splitJoinBlake3 hashes n pieces of data concurrently and returns the
+// hash of their concatenated digests.
+func splitJoinBlake3(data []byte, n int) [32]byte {
+	digests := make([]byte, 32*n)
+	piece := (len(data) + n - 1) / n
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		lo, hi := min(i*piece, len(data)), min((i+1)*piece, len(data))
+		wg.Go(func() {
+			d := blake3.Sum256(data[lo:hi])
+			copy(digests[32*i:], d[:])
+		})
+	}
+	wg.Wait()
+	return blake3.Sum256(digests)
+}
+
+func TestSplitJoinBlake3(t *testing.T) {
+	data := benchutil.Bytes(blake3Size)
+	whole := blake3.Sum256(data)
+	if splitJoinBlake3(data, 1) != blake3.Sum256(whole[:]) {
+		t.Error("a single piece is not the hash of the digest of the message")
+	}
+	if splitJoinBlake3(data, 4) != splitJoinBlake3(data, 4) {
+		t.Error("the result depends on the goroutine schedule")
+	}
+}
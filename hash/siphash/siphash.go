@@ -0,0 +1,140 @@
+// Package siphash implements SipHash-2-4 with 64-bit output, the keyed
+// hash of Aumasson and Bernstein (https://www.aumasson.jp/siphash/)
+// used by many hash tables to resist hash flooding.
+package siphash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Size is the size of a checksum in bytes.
+const Size = 8
+
+// BlockSize is the number of bytes consumed per compression.
+const BlockSize = 8
+
+// KeySize is the size of the key in bytes.
+const KeySize = 16
+
+// Sum64 returns the SipHash-2-4 of b under the key (k0, k1), the
+// little-endian halves of the 16 byte key.
+func Sum64(k0, k1 uint64, b []byte) uint64 {
+	d := Digest{k0: k0, k1: k1}
+	d.Reset()
+	d.Write(b)
+	return d.Sum64()
+}
+
+// Digest is the streaming state of SipHash-2-4, it implements
+// hash.Hash64.
+type Digest struct {
+	k0, k1         uint64
+	v0, v1, v2, v3 uint64
+	total          uint64
+	buf            [BlockSize]byte
+	n              int // bytes in buf
+}
+
+// New returns a Digest keyed with key, it panics if key is not KeySize
+// bytes long.
+func New(key []byte) *Digest {
+	if len(key) != KeySize {
+		panic("siphash: key must be 16 bytes")
+	}
+	d := &Digest{
+		k0: binary.LittleEndian.Uint64(key),
+		k1: binary.LittleEndian.Uint64(key[8:]),
+	}
+	d.Reset()
+	return d
+}
+
+// Reset restores the initial state of d, keeping the key.
+func (d *Digest) Reset() {
+	d.v0 = d.k0 ^ 0x736f6d6570736575
+	d.v1 = d.k1 ^ 0x646f72616e646f6d
+	d.v2 = d.k0 ^ 0x6c7967656e657261
+	d.v3 = d.k1 ^ 0x7465646279746573
+	d.total = 0
+	d.n = 0
+}
+
+// Size returns Size.
+func (d *Digest) Size() int { return Size }
+
+// BlockSize returns BlockSize.
+func (d *Digest) BlockSize() int { return BlockSize }
+
+// Write adds b to the hashed stream, it never returns an error.
+func (d *Digest) Write(b []byte) (int, error) {
+	n := len(b)
+	d.total += uint64(n)
+
+	if d.n > 0 {
+		c := copy(d.buf[d.n:], b)
+		d.n += c
+		b = b[c:]
+		if d.n < BlockSize {
+			return n, nil
+		}
+		d.compress(binary.LittleEndian.Uint64(d.buf[:]))
+		d.n = 0
+	}
+	for ; len(b) >= BlockSize; b = b[BlockSize:] {
+		d.compress(binary.LittleEndian.Uint64(b))
+	}
+	d.n = copy(d.buf[:], b)
+	return n, nil
+}
+
+// compress runs the two compression rounds on the message word m.
+func (d *Digest) compress(m uint64) {
+	v0, v1, v2, v3 := d.v0, d.v1, d.v2, d.v3^m
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	d.v0, d.v1, d.v2, d.v3 = v0^m, v1, v2, v3
+}
+
+// Sum appends the little-endian checksum to b, the byte order of the
+// test vectors in the paper.
+func (d *Digest) Sum(b []byte) []byte {
+	return binary.LittleEndian.AppendUint64(b, d.Sum64())
+}
+
+// Sum64 returns the checksum of the stream so far, d is not modified.
+func (d *Digest) Sum64() uint64 {
+	var last [BlockSize]byte
+	copy(last[:], d.buf[:d.n])
+	last[7] = byte(d.total)
+	m := binary.LittleEndian.Uint64(last[:])
+
+	v0, v1, v2, v3 := d.v0, d.v1, d.v2, d.v3^m
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	v0 ^= m
+
+	v2 ^= 0xff
+	for i := 0; i < 4; i++ {
+		v0, v1, v2, v3 = sipRound(v0, v1, v2, v3)
+	}
+	return v0 ^ v1 ^ v2 ^ v3
+}
+
+func sipRound(v0, v1, v2, v3 uint64) (uint64, uint64, uint64, uint64) {
+	v0 += v1
+	v1 = bits.RotateLeft64(v1, 13)
+	v1 ^= v0
+	v0 = bits.RotateLeft64(v0, 32)
+	v2 += v3
+	v3 = bits.RotateLeft64(v3, 16)
+	v3 ^= v2
+	v0 += v3
+	v3 = bits.RotateLeft64(v3, 21)
+	v3 ^= v0
+	v2 += v1
+	v1 = bits.RotateLeft64(v1, 17)
+	v1 ^= v2
+	v2 = bits.RotateLeft64(v2, 32)
+	return v0, v1, v2, v3
+}
@@ -0,0 +1,160 @@
+package hash
+
+import (
+	"bytes"
+	"encoding"
+	"io"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// streamSize is the length of the stream hashed by BenchmarkHashStream,
+// large enough that the chunk size and not the setup dominates.
+const streamSize = 8 << 20
+
+// streamChunks are the buffer sizes io.CopyBuffer reads the stream with,
+// from a small pipe buffer to a large file read.
+var streamChunks = []int{512, 4 << 10, 32 << 10, 256 << 10, 1 << 20}
+
+// chunkReader hides the WriterTo of bytes.Reader, otherwise io.CopyBuffer
+// would hand the whole stream to the hash in a single Write.
+type chunkReader struct {
+	io.Reader
+}
+
+// BenchmarkHashStream hashes a stream the way a large file is hashed:
+// io.CopyBuffer from a reader into the hash, one chunk per Write.
+func BenchmarkHashStream(b *testing.B) {
+	data := benchutil.Bytes(streamSize)
+	for _, algo := range hashAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			benchutil.Sizes(b, streamChunks, func(b *testing.B, chunk int) {
+				h := algo.new()
+				buf := make([]byte, chunk)
+				b.SetBytes(streamSize)
+				for i := 0; i < b.N; i++ {
+					h.Reset()
+					if _, err := io.CopyBuffer(h, chunkReader{bytes.NewReader(data)}, buf); err != nil {
+						b.Fatal(err)
+					}
+					benchutil.BytesSink.Store(h.Sum(nil))
+				}
+			})
+		})
+	}
+}
+
+// runningChunk is the size of every Write of BenchmarkHashRunningSum.
+const runningChunk = 1 << 10
+
+// BenchmarkHashRunningSum appends to a hash that is never reset, as a
+// log that publishes its digest after every record does. WriteSum takes
+// the digest of the running state after every Write, Write alone shows
+// how much of that is the Sum.
+func BenchmarkHashRunningSum(b *testing.B) {
+	data := benchutil.Bytes(runningChunk)
+	for _, algo := range hashAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			b.Run("Write", func(b *testing.B) {
+				h := algo.new()
+				b.SetBytes(runningChunk)
+				for i := 0; i < b.N; i++ {
+					h.Write(data)
+				}
+				benchutil.BytesSink.Store(h.Sum(nil))
+			})
+			b.Run("WriteSum", func(b *testing.B) {
+				h := algo.new()
+				sum := make([]byte, 0, h.Size())
+				b.SetBytes(runningChunk)
+				for i := 0; i < b.N; i++ {
+					h.Write(data)
+					sum = h.Sum(sum[:0])
+				}
+				benchutil.BytesSink.Store(sum)
+			})
+		})
+	}
+}
+
+// BenchmarkHashSnapshot measures saving and restoring the state of a
+// hash in the middle of a stream via encoding.BinaryMarshaler, which
+// allows resuming an interrupted upload without hashing it again.
+// Algorithms without a marshalable state are left out.
+func BenchmarkHashSnapshot(b *testing.B) {
+	data := benchutil.Bytes(runningChunk)
+	for _, algo := range hashAlgos {
+		h := algo.new()
+		h.Write(data)
+		m, ok := h.(encoding.BinaryMarshaler)
+		if !ok {
+			continue
+		}
+		state, err := m.MarshalBinary()
+		if err != nil {
+			b.Fatalf("%s: %v", algo.name, err)
+		}
+
+		b.Run(algo.name, func(b *testing.B) {
+			b.Run("MarshalBinary", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					s, err := m.MarshalBinary()
+					if err != nil {
+						b.Fatal(err)
+					}
+					benchutil.BytesSink.Store(s)
+				}
+			})
+			if a, ok := h.(encoding.BinaryAppender); ok {
+				b.Run("AppendBinary", func(b *testing.B) {
+					buf := make([]byte, 0, len(state))
+					for i := 0; i < b.N; i++ {
+						s, err := a.AppendBinary(buf[:0])
+						if err != nil {
+							b.Fatal(err)
+						}
+						buf = s
+					}
+					benchutil.BytesSink.Store(buf)
+				})
+			}
+			b.Run("UnmarshalBinary", func(b *testing.B) {
+				restored := algo.new().(encoding.BinaryUnmarshaler)
+				for i := 0; i < b.N; i++ {
+					if err := restored.UnmarshalBinary(state); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+// TestHashSnapshot checks that a restored state continues the stream
+// exactly where the snapshot was taken.
+func TestHashSnapshot(t *testing.T) {
+	data := benchutil.Bytes(3 * runningChunk)
+	for _, algo := range hashAlgos {
+		h := algo.new()
+		m, ok := h.(encoding.BinaryMarshaler)
+		if !ok {
+			continue
+		}
+		h.Write(data[:runningChunk])
+		state, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: %v", algo.name, err)
+		}
+		h.Write(data[runningChunk:])
+
+		restored := algo.new()
+		if err := restored.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+			t.Fatalf("%s: %v", algo.name, err)
+		}
+		restored.Write(data[runningChunk:])
+		if !bytes.Equal(restored.Sum(nil), h.Sum(nil)) {
+			t.Errorf("%s: restored state hashes differently", algo.name)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package wyhash implements version 4.2 of wyhash by Wang Yi
+// (https://github.com/wangyi-fudan/wyhash), the design the Go runtime
+// map hash falls back to without AES instructions. wyhash has no
+// streaming form, the whole key is needed up front.
+package wyhash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// secret is the default secret, _wyp in wyhash.h.
+var secret = [4]uint64{0x2d358dccaa6c78a5, 0x8bb84b93962eacc9, 0x4b33a62ed433d4a3, 0x4d5a2da51de1aa47}
+
+// Hash returns the wyhash of b with the given seed and the default
+// secret.
+func Hash(b []byte, seed uint64) uint64 {
+	n := len(b)
+	seed ^= mix(seed^secret[0], secret[1])
+
+	var a, c uint64
+	switch {
+	case n >= 4 && n <= 16:
+		off := (n >> 3) << 2
+		a = r4(b)<<32 | r4(b[off:])
+		c = r4(b[n-4:])<<32 | r4(b[n-4-off:])
+	case n > 0 && n < 4:
+		a = uint64(b[0])<<16 | uint64(b[n>>1])<<8 | uint64(b[n-1])
+	case n > 16:
+		p := b
+		if len(p) >= 48 {
+			see1, see2 := seed, seed
+			for ; len(p) >= 48; p = p[48:] {
+				seed = mix(r8(p)^secret[1], r8(p[8:])^seed)
+				see1 = mix(r8(p[16:])^secret[2], r8(p[24:])^see1)
+				see2 = mix(r8(p[32:])^secret[3], r8(p[40:])^see2)
+			}
+			seed ^= see1 ^ see2
+		}
+		for ; len(p) > 16; p = p[16:] {
+			seed = mix(r8(p)^secret[1], r8(p[8:])^seed)
+		}
+		// The last 16 bytes are read from the end of b and may overlap
+		// bytes already mixed in.
+		a = r8(b[n-16:])
+		c = r8(b[n-8:])
+	}
+
+	a, c = mum(a^secret[1], c^seed)
+	return mix(a^secret[0]^uint64(n), c^secret[1])
+}
+
+// mum multiplies a and b to 128 bits and returns the low and high half.
+func mum(a, b uint64) (uint64, uint64) {
+	hi, lo := bits.Mul64(a, b)
+	return lo, hi
+}
+
+func mix(a, b uint64) uint64 {
+	lo, hi := mum(a, b)
+	return lo ^ hi
+}
+
+func r8(b []byte) uint64 { return binary.LittleEndian.Uint64(b) }
+func r4(b []byte) uint64 { return uint64(binary.LittleEndian.Uint32(b)) }
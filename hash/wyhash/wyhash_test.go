@@ -0,0 +1,25 @@
+package wyhash
+
+import "testing"
+
+// vectors are the test vectors of wyhash.h, the seed is the index.
+var vectors = []struct {
+	in   string
+	want uint64
+}{
+	{"", 0x93228a4de0eec5a2},
+	{"a", 0xc5bac3db178713c4},
+	{"abc", 0xa97f2f7b1d9b3314},
+	{"message digest", 0x786d1f1df3801df4},
+	{"abcdefghijklmnopqrstuvwxyz", 0xdca5a8138ad37c87},
+	{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", 0xb9e734f117cfaf70},
+	{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", 0x6cc5eab49a92d617},
+}
+
+func TestKnownAnswers(t *testing.T) {
+	for seed, v := range vectors {
+		if got := Hash([]byte(v.in), uint64(seed)); got != v.want {
+			t.Errorf("Hash(%q, %d) = %#016x, want %#016x", v.in, seed, got, v.want)
+		}
+	}
+}
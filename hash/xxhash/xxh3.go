@@ -0,0 +1,164 @@
+package xxhash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	prime32_1 = 0x9e3779b1
+	prime32_2 = 0x85ebca77
+	prime32_3 = 0xc2b2ae3d
+)
+
+// secret is the default secret of XXH3, kSecret in the reference
+// implementation.
+var secret = [192]byte{
+	0xb8, 0xfe, 0x6c, 0x39, 0x23, 0xa4, 0x4b, 0xbe, 0x7c, 0x01, 0x81, 0x2c, 0xf7, 0x21, 0xad, 0x1c,
+	0xde, 0xd4, 0x6d, 0xe9, 0x83, 0x90, 0x97, 0xdb, 0x72, 0x40, 0xa4, 0xa4, 0xb7, 0xb3, 0x67, 0x1f,
+	0xcb, 0x79, 0xe6, 0x4e, 0xcc, 0xc0, 0xe5, 0x78, 0x82, 0x5a, 0xd0, 0x7d, 0xcc, 0xff, 0x72, 0x21,
+	0xb8, 0x08, 0x46, 0x74, 0xf7, 0x43, 0x24, 0x8e, 0xe0, 0x35, 0x90, 0xe6, 0x81, 0x3a, 0x26, 0x4c,
+	0x3c, 0x28, 0x52, 0xbb, 0x91, 0xc3, 0x00, 0xcb, 0x88, 0xd0, 0x65, 0x8b, 0x1b, 0x53, 0x2e, 0xa3,
+	0x71, 0x64, 0x48, 0x97, 0xa2, 0x0d, 0xf9, 0x4e, 0x38, 0x19, 0xef, 0x46, 0xa9, 0xde, 0xac, 0xd8,
+	0xa8, 0xfa, 0x76, 0x3f, 0xe3, 0x9c, 0x34, 0x3f, 0xf9, 0xdc, 0xbb, 0xc7, 0xc7, 0x0b, 0x4f, 0x1d,
+	0x8a, 0x51, 0xe0, 0x4b, 0xcd, 0xb4, 0x59, 0x31, 0xc8, 0x9f, 0x7e, 0xc9, 0xd9, 0x78, 0x73, 0x64,
+	0xea, 0xc5, 0xac, 0x83, 0x34, 0xd3, 0xeb, 0xc3, 0xc5, 0x81, 0xa0, 0xff, 0xfa, 0x13, 0x63, 0xeb,
+	0x17, 0x0d, 0xdd, 0x51, 0xb7, 0xf0, 0xda, 0x49, 0xd3, 0x16, 0x55, 0x26, 0x29, 0xd4, 0x68, 0x9e,
+	0x2b, 0x16, 0xbe, 0x58, 0x7d, 0x47, 0xa1, 0xfc, 0x8f, 0xf8, 0xb8, 0xd1, 0x7a, 0xd0, 0x31, 0xce,
+	0x45, 0xcb, 0x3a, 0x8f, 0x95, 0x16, 0x04, 0x28, 0xaf, 0xd7, 0xfb, 0xca, 0xbb, 0x4b, 0x40, 0x7e,
+}
+
+const (
+	stripeLen       = 64
+	stripesPerBlock = (len(secret) - stripeLen) / 8
+	blockLen        = stripeLen * stripesPerBlock
+)
+
+// Sum3 returns the 64-bit XXH3 checksum of b with seed 0 and the default
+// secret. Unlike XXH64 it picks a different code path per input length,
+// which is what makes it fast on the short keys of hash tables.
+func Sum3(b []byte) uint64 {
+	n := len(b)
+	switch {
+	case n == 0:
+		return avalanche64(le64(secret[56:]) ^ le64(secret[64:]))
+	case n <= 3:
+		c := uint32(b[0])<<16 | uint32(b[n>>1])<<24 | uint32(b[n-1]) | uint32(n)<<8
+		return avalanche64(uint64(c) ^ uint64(le32(secret[0:])^le32(secret[4:])))
+	case n <= 8:
+		in := uint64(le32(b[n-4:])) | uint64(le32(b))<<32
+		return rrmxmx(in^(le64(secret[8:])^le64(secret[16:])), uint64(n))
+	case n <= 16:
+		lo := le64(b) ^ (le64(secret[24:]) ^ le64(secret[32:]))
+		hi := le64(b[n-8:]) ^ (le64(secret[40:]) ^ le64(secret[48:]))
+		return avalanche3(uint64(n) + bits.ReverseBytes64(lo) + hi + fold64(lo, hi))
+	case n <= 128:
+		acc := uint64(n) * prime64_1
+		if n > 32 {
+			if n > 64 {
+				if n > 96 {
+					acc += mix16(b[48:], secret[96:])
+					acc += mix16(b[n-64:], secret[112:])
+				}
+				acc += mix16(b[32:], secret[64:])
+				acc += mix16(b[n-48:], secret[80:])
+			}
+			acc += mix16(b[16:], secret[32:])
+			acc += mix16(b[n-32:], secret[48:])
+		}
+		acc += mix16(b, secret[:])
+		acc += mix16(b[n-16:], secret[16:])
+		return avalanche3(acc)
+	case n <= 240:
+		acc := uint64(n) * prime64_1
+		for i := 0; i < 8; i++ {
+			acc += mix16(b[16*i:], secret[16*i:])
+		}
+		acc = avalanche3(acc)
+		for i := 8; i < n/16; i++ {
+			acc += mix16(b[16*i:], secret[16*(i-8)+3:])
+		}
+		acc += mix16(b[n-16:], secret[136-17:])
+		return avalanche3(acc)
+	default:
+		return hashLong(b)
+	}
+}
+
+// hashLong is XXH3 for inputs above 240 bytes: eight accumulators take
+// 64 byte stripes and are scrambled after every block of 1 KiB.
+func hashLong(b []byte) uint64 {
+	acc := [8]uint64{
+		prime32_3, prime64_1, prime64_2, prime64_3,
+		prime64_4, prime32_2, prime64_5, prime32_1,
+	}
+	n := len(b)
+	blocks := (n - 1) / blockLen
+	for i := 0; i < blocks; i++ {
+		block := b[i*blockLen:]
+		for s := 0; s < stripesPerBlock; s++ {
+			accumulate(&acc, block[s*stripeLen:], secret[s*8:])
+		}
+		scramble(&acc, secret[len(secret)-stripeLen:])
+	}
+
+	tail := b[blocks*blockLen:]
+	stripes := (n - 1 - blocks*blockLen) / stripeLen
+	for s := 0; s < stripes; s++ {
+		accumulate(&acc, tail[s*stripeLen:], secret[s*8:])
+	}
+	accumulate(&acc, b[n-stripeLen:], secret[len(secret)-stripeLen-7:])
+
+	h := uint64(n) * prime64_1
+	for i := 0; i < 4; i++ {
+		h += fold64(acc[2*i]^le64(secret[11+16*i:]), acc[2*i+1]^le64(secret[11+16*i+8:]))
+	}
+	return avalanche3(h)
+}
+
+func accumulate(acc *[8]uint64, stripe, key []byte) {
+	for i := 0; i < 8; i++ {
+		v := le64(stripe[8*i:])
+		k := v ^ le64(key[8*i:])
+		acc[i^1] += v
+		acc[i] += uint64(uint32(k)) * (k >> 32)
+	}
+}
+
+func scramble(acc *[8]uint64, key []byte) {
+	for i := range acc {
+		a := acc[i]
+		a ^= a >> 47
+		a ^= le64(key[8*i:])
+		acc[i] = a * prime32_1
+	}
+}
+
+func mix16(b, key []byte) uint64 {
+	return fold64(le64(b)^le64(key), le64(b[8:])^le64(key[8:]))
+}
+
+// fold64 multiplies to 128 bits and folds the halves with xor.
+func fold64(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func avalanche3(h uint64) uint64 {
+	h ^= h >> 37
+	h *= 0x165667919e3779f9
+	h ^= h >> 32
+	return h
+}
+
+func rrmxmx(h, n uint64) uint64 {
+	h ^= bits.RotateLeft64(h, 49) ^ bits.RotateLeft64(h, 24)
+	h *= 0x9fb21c651e98df25
+	h ^= (h >> 35) + n
+	h *= 0x9fb21c651e98df25
+	h ^= h >> 28
+	return h
+}
+
+func le64(b []byte) uint64 { return binary.LittleEndian.Uint64(b) }
+func le32(b []byte) uint32 { return binary.LittleEndian.Uint32(b) }
@@ -0,0 +1,163 @@
+// Package xxhash implements the 64-bit xxHash algorithms XXH64 and XXH3
+// in pure Go, as specified at https://github.com/Cyan4973/xxHash.
+// It exists so the hash benchmarks can compare them without assembly
+// and without a dependency; the known-answer tests pin the output.
+package xxhash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+const (
+	prime64_1 = 0x9e3779b185ebca87
+	prime64_2 = 0xc2b2ae3d27d4eb4f
+	prime64_3 = 0x165667b19e3779f9
+	prime64_4 = 0x85ebca77c2b2ae63
+	prime64_5 = 0x27d4eb2f165667c5
+)
+
+// Size is the size of a checksum in bytes.
+const Size = 8
+
+// BlockSize is the number of bytes XXH64 consumes per round.
+const BlockSize = 32
+
+// Sum64 returns the XXH64 checksum of b with seed 0.
+func Sum64(b []byte) uint64 {
+	d := New()
+	d.Write(b)
+	return d.Sum64()
+}
+
+// Digest is the streaming state of XXH64, it implements hash.Hash64.
+type Digest struct {
+	seed  uint64
+	v     [4]uint64
+	total uint64
+	buf   [BlockSize]byte
+	n     int // bytes in buf
+}
+
+// New returns a Digest with seed 0.
+func New() *Digest {
+	return NewWithSeed(0)
+}
+
+// NewWithSeed returns a Digest with the given seed.
+func NewWithSeed(seed uint64) *Digest {
+	d := &Digest{seed: seed}
+	d.Reset()
+	return d
+}
+
+// Reset restores the initial state of d, keeping the seed.
+func (d *Digest) Reset() {
+	d.v = [4]uint64{
+		d.seed + prime64_1 + prime64_2,
+		d.seed + prime64_2,
+		d.seed,
+		d.seed - prime64_1,
+	}
+	d.total = 0
+	d.n = 0
+}
+
+// Size returns Size.
+func (d *Digest) Size() int { return Size }
+
+// BlockSize returns BlockSize.
+func (d *Digest) BlockSize() int { return BlockSize }
+
+// Write adds b to the hashed stream, it never returns an error.
+func (d *Digest) Write(b []byte) (int, error) {
+	n := len(b)
+	d.total += uint64(n)
+
+	if d.n > 0 {
+		c := copy(d.buf[d.n:], b)
+		d.n += c
+		b = b[c:]
+		if d.n < BlockSize {
+			return n, nil
+		}
+		d.blocks(d.buf[:])
+		d.n = 0
+	}
+	if len(b) >= BlockSize {
+		full := len(b) &^ (BlockSize - 1)
+		d.blocks(b[:full])
+		b = b[full:]
+	}
+	d.n = copy(d.buf[:], b)
+	return n, nil
+}
+
+func (d *Digest) blocks(b []byte) {
+	v0, v1, v2, v3 := d.v[0], d.v[1], d.v[2], d.v[3]
+	for ; len(b) >= BlockSize; b = b[BlockSize:] {
+		v0 = round(v0, binary.LittleEndian.Uint64(b[0:]))
+		v1 = round(v1, binary.LittleEndian.Uint64(b[8:]))
+		v2 = round(v2, binary.LittleEndian.Uint64(b[16:]))
+		v3 = round(v3, binary.LittleEndian.Uint64(b[24:]))
+	}
+	d.v = [4]uint64{v0, v1, v2, v3}
+}
+
+// Sum appends the big-endian checksum to b.
+func (d *Digest) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint64(b, d.Sum64())
+}
+
+// Sum64 returns the checksum of the stream so far, d is not modified.
+func (d *Digest) Sum64() uint64 {
+	var h uint64
+	if d.total >= BlockSize {
+		v0, v1, v2, v3 := d.v[0], d.v[1], d.v[2], d.v[3]
+		h = bits.RotateLeft64(v0, 1) + bits.RotateLeft64(v1, 7) +
+			bits.RotateLeft64(v2, 12) + bits.RotateLeft64(v3, 18)
+		h = mergeRound(h, v0)
+		h = mergeRound(h, v1)
+		h = mergeRound(h, v2)
+		h = mergeRound(h, v3)
+	} else {
+		h = d.seed + prime64_5
+	}
+	h += d.total
+
+	b := d.buf[:d.n]
+	for ; len(b) >= 8; b = b[8:] {
+		h ^= round(0, binary.LittleEndian.Uint64(b))
+		h = bits.RotateLeft64(h, 27)*prime64_1 + prime64_4
+	}
+	if len(b) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(b)) * prime64_1
+		h = bits.RotateLeft64(h, 23)*prime64_2 + prime64_3
+		b = b[4:]
+	}
+	for _, c := range b {
+		h ^= uint64(c) * prime64_5
+		h = bits.RotateLeft64(h, 11) * prime64_1
+	}
+	return avalanche64(h)
+}
+
+func round(acc, input uint64) uint64 {
+	acc += input * prime64_2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * prime64_1
+}
+
+func mergeRound(acc, v uint64) uint64 {
+	acc ^= round(0, v)
+	return acc*prime64_1 + prime64_4
+}
+
+func avalanche64(h uint64) uint64 {
+	h ^= h >> 33
+	h *= prime64_2
+	h ^= h >> 29
+	h *= prime64_3
+	h ^= h >> 32
+	return h
+}
@@ -0,0 +1,86 @@
+package xxhash
+
+import (
+	"fmt"
+	"testing"
+)
+
+// pattern returns the bytes 0, 1, 2, … of length n.
+func pattern(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i)
+	}
+	return b
+}
+
+// The expected values were produced by the reference implementation,
+// lengths are chosen to hit every size class of XXH3.
+var stringVectors = []struct {
+	in    string
+	xxh64 uint64
+	xxh3  uint64
+}{
+	{"", 0xef46db3751d8e999, 0x2d06800538d394c2},
+	{"a", 0xd24ec4f1a98c6e5b, 0xe6c632b61e964e1f},
+	{"abc", 0x44bc2cf5ad770999, 0x78af5f94892f3950},
+	{"message digest", 0x066ed728fceeb3be, 0x160d8e9329be94f9},
+	{"abcdefghijklmnopqrstuvwxyz", 0xcfe1f278fa89835c, 0x810f9ca067fbb90c},
+	{"The quick brown fox jumps over the lazy dog", 0x0b242d361fda71bc, 0xce7d19a5418fb365},
+}
+
+var patternVectors = []struct {
+	n           int
+	xxh64, xxh3 uint64
+}{
+	{3, 0xe5c7bb4533bc65dd, 0x5f4299fc161c9cbb},
+	{4, 0xffced8604453cc1e, 0x60dab036a58211f2},
+	{8, 0x884a173614b81b8d, 0x3a1c2d7c85af88f8},
+	{9, 0x67d85784a7c78c5b, 0xe9612598145bb9dc},
+	{16, 0x44b6ef2fb84169f7, 0x8355e3a6f61770db},
+	{17, 0x5603e60c527599b6, 0x9ef341a99de37328},
+	{64, 0xf7c67301db6713f0, 0x6187eb9089b0ed55},
+	{100, 0x6ac1e58032166597, 0x004e4f921a64bd1c},
+	{128, 0x7a7fe14647b9ab92, 0x85c6174c7ff4c46b},
+	{129, 0x0ba25dfd6e891fcf, 0xec7642b431ba3e5a},
+	{240, 0x012947f0da6a27b1, 0x375a384d957fe865},
+	{241, 0x8d643f23bf2808e1, 0x02e8cd95421c6d02},
+	{1024, 0x6f3914f18fe4df57, 0xa870f92984398d22},
+	{1025, 0x0614c40149130943, 0x78c86e91ee939852},
+	{4096, 0x0f6e64be186af6a4, 0xeb4b7c3707879151},
+	{10000, 0x03232d083fda7b67, 0x3eee77440c4c3d08},
+}
+
+func TestKnownAnswers(t *testing.T) {
+	check := func(name string, in []byte, xxh64, xxh3 uint64) {
+		if got := Sum64(in); got != xxh64 {
+			t.Errorf("Sum64(%s) = %#016x, want %#016x", name, got, xxh64)
+		}
+		if got := Sum3(in); got != xxh3 {
+			t.Errorf("Sum3(%s) = %#016x, want %#016x", name, got, xxh3)
+		}
+	}
+	for _, v := range stringVectors {
+		check(fmt.Sprintf("%q", v.in), []byte(v.in), v.xxh64, v.xxh3)
+	}
+	for _, v := range patternVectors {
+		check(fmt.Sprintf("pattern(%d)", v.n), pattern(v.n), v.xxh64, v.xxh3)
+	}
+}
+
+func TestDigestStreaming(t *testing.T) {
+	for _, v := range patternVectors {
+		in := pattern(v.n)
+		for _, step := range []int{1, 7, 31, 32, 33, 1000} {
+			d := New()
+			for p := in; len(p) > 0; {
+				k := min(step, len(p))
+				d.Write(p[:k])
+				p = p[k:]
+			}
+			if got := d.Sum64(); got != v.xxh64 {
+				t.Errorf("pattern(%d) in writes of %d = %#016x, want %#016x", v.n, step, got, v.xxh64)
+			}
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package hash_crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// aeadAlgos are the authenticated ciphers TLS and most storage formats
+// choose from. XChaCha20-Poly1305 takes a 24 byte nonce, large enough to
+// pick at random for every message.
+var aeadAlgos = []struct {
+	name    string
+	keySize int
+	new     func(key []byte) (cipher.AEAD, error)
+}{
+	{"AES128GCM", 16, newGCM},
+	{"AES256GCM", 32, newGCM},
+	{"ChaCha20Poly1305", chacha20poly1305.KeySize, chacha20poly1305.New},
+	{"XChaCha20Poly1305", chacha20poly1305.KeySize, chacha20poly1305.NewX},
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// aeadSizes go from a small record to a large file chunk.
+var aeadSizes = []int{64, 1 << 10, 16 << 10, 1 << 20}
+
+// mustAEAD returns the cipher of algo under a deterministic key and a
+// nonce of the right size. The benchmarks reuse the nonce, which is only
+// acceptable because nothing they encrypt is ever sent anywhere.
+func mustAEAD(tb testing.TB, keySize int, newAEAD func([]byte) (cipher.AEAD, error)) (cipher.AEAD, []byte) {
+	aead, err := newAEAD(benchutil.Bytes(keySize))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return aead, make([]byte, aead.NonceSize())
+}
+
+func BenchmarkAEADSeal(b *testing.B) {
+	data := benchutil.Bytes(aeadSizes[len(aeadSizes)-1])
+	for _, algo := range aeadAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			benchutil.Sizes(b, aeadSizes, func(b *testing.B, n int) {
+				aead, nonce := mustAEAD(b, algo.keySize, algo.new)
+				dst := make([]byte, 0, n+aead.Overhead())
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					dst = aead.Seal(dst[:0], nonce, data[:n], nil)
+				}
+				benchutil.BytesSink.Store(dst)
+			})
+		})
+	}
+}
+
+func BenchmarkAEADOpen(b *testing.B) {
+	data := benchutil.Bytes(aeadSizes[len(aeadSizes)-1])
+	for _, algo := range aeadAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			benchutil.Sizes(b, aeadSizes, func(b *testing.B, n int) {
+				aead, nonce := mustAEAD(b, algo.keySize, algo.new)
+				sealed := aead.Seal(nil, nonce, data[:n], nil)
+				dst := make([]byte, 0, n)
+				b.SetBytes(int64(n))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					var err error
+					dst, err = aead.Open(dst[:0], nonce, sealed, nil)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				benchutil.BytesSink.Store(dst)
+			})
+		})
+	}
+}
+
+// BenchmarkAEADKeygen draws a random key and sets up the cipher, the
+// cost paid per connection or per file.
+func BenchmarkAEADKeygen(b *testing.B) {
+	for _, algo := range aeadAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			key := make([]byte, algo.keySize)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				rand.Read(key)
+				aead, err := algo.new(key)
+				if err != nil {
+					b.Fatal(err)
+				}
+				aeadSink.Store(aead)
+			}
+		})
+	}
+}
+
+var aeadSink benchutil.Sink[cipher.AEAD]
+
+func TestAEADs(t *testing.T) {
+	msg := benchutil.Bytes(1000)
+	ad := []byte("header")
+	for _, algo := range aeadAlgos {
+		aead, nonce := mustAEAD(t, algo.keySize, algo.new)
+		sealed := aead.Seal(nil, nonce, msg, ad)
+		if len(sealed) != len(msg)+aead.Overhead() {
+			t.Errorf("%s: sealed %d bytes into %d", algo.name, len(msg), len(sealed))
+		}
+		opened, err := aead.Open(nil, nonce, sealed, ad)
+		if err != nil || !bytes.Equal(opened, msg) {
+			t.Errorf("%s: Open = %v, want the message back", algo.name, err)
+		}
+		sealed[0] ^= 1
+		if _, err := aead.Open(nil, nonce, sealed, ad); err == nil {
+			t.Errorf("%s: Open accepts a modified ciphertext", algo.name)
+		}
+	}
+}
@@ -1,23 +1,29 @@
+// Package hash_crypto benchmarks cryptographic primitives: hashes,
+// authenticated ciphers, signatures and key exchange, each with the cost
+// of generating its keys.
 package hash_crypto
 
 import (
 	"crypto/sha256"
 	"testing"
 
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 	"golang.org/x/crypto/blake2b"
 )
 
-var sample = []byte("the quick brown fox jumps over the lazy dog")
+var (
+	sample  = []byte("the quick brown fox jumps over the lazy dog")
+	sumSink benchutil.Sink[[32]byte]
+)
 
 func BenchmarkSHA256(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		h := sha256.Sum256(sample)
-		_ = h
+		sumSink.Store(sha256.Sum256(sample))
 	}
 }
 
 func BenchmarkBLAKE2b(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		_ = blake2b.Sum256(sample)
+		sumSink.Store(blake2b.Sum256(sample))
 	}
 }
@@ -0,0 +1,164 @@
+package hash_crypto
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"crypto/rand"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// ecdhCurves are the curves of the classic key exchanges, X25519 first as
+// the default of TLS 1.3.
+var ecdhCurves = []struct {
+	name  string
+	curve ecdh.Curve
+}{
+	{"X25519", ecdh.X25519()},
+	{"P256", ecdh.P256()},
+	{"P384", ecdh.P384()},
+	{"P521", ecdh.P521()},
+}
+
+// BenchmarkECDH times both halves of an ephemeral exchange: generating
+// the key pair and deriving the shared secret from the peer's public key.
+func BenchmarkECDH(b *testing.B) {
+	for _, c := range ecdhCurves {
+		b.Run(c.name, func(b *testing.B) {
+			b.Run("GenerateKey", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					key, err := c.curve.GenerateKey(rand.Reader)
+					if err != nil {
+						b.Fatal(err)
+					}
+					ecdhSink.Store(key)
+				}
+			})
+			b.Run("ECDH", func(b *testing.B) {
+				local, peer := ecdhPair(b, c.curve)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					secret, err := local.ECDH(peer.PublicKey())
+					if err != nil {
+						b.Fatal(err)
+					}
+					benchutil.BytesSink.Store(secret)
+				}
+			})
+		})
+	}
+}
+
+var ecdhSink benchutil.Sink[*ecdh.PrivateKey]
+
+func ecdhPair(tb testing.TB, curve ecdh.Curve) (*ecdh.PrivateKey, *ecdh.PrivateKey) {
+	local, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	peer, err := curve.GenerateKey(rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return local, peer
+}
+
+// decapsulationKey is what the ML-KEM parameter sets have in common: the
+// receiver generates a decapsulation key, the sender encapsulates a
+// shared key to its public half and the receiver decapsulates it.
+type decapsulationKey[E encapsulationKey] interface {
+	Decapsulate(ciphertext []byte) ([]byte, error)
+	EncapsulationKey() E
+}
+
+type encapsulationKey interface {
+	Encapsulate() (sharedKey, ciphertext []byte)
+}
+
+// BenchmarkMLKEM times the three steps of an ML-KEM exchange, together
+// the post-quantum half of the hybrid key exchange of TLS 1.3.
+func BenchmarkMLKEM(b *testing.B) {
+	b.Run("MLKEM768", func(b *testing.B) {
+		benchmarkMLKEM[*mlkem.EncapsulationKey768](b, mlkem.GenerateKey768)
+	})
+	b.Run("MLKEM1024", func(b *testing.B) {
+		benchmarkMLKEM[*mlkem.EncapsulationKey1024](b, mlkem.GenerateKey1024)
+	})
+}
+
+func benchmarkMLKEM[E encapsulationKey, D decapsulationKey[E]](b *testing.B, generate func() (D, error)) {
+	b.Run("GenerateKey", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dk, err := generate()
+			if err != nil {
+				b.Fatal(err)
+			}
+			benchutil.AnySink.Store(dk)
+		}
+	})
+	dk, err := generate()
+	if err != nil {
+		b.Fatal(err)
+	}
+	ek := dk.EncapsulationKey()
+	b.Run("Encapsulate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			shared, ct := ek.Encapsulate()
+			benchutil.BytesSink.Store(shared)
+			benchutil.BytesSink.Store(ct)
+		}
+	})
+	b.Run("Decapsulate", func(b *testing.B) {
+		_, ct := ek.Encapsulate()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			shared, err := dk.Decapsulate(ct)
+			if err != nil {
+				b.Fatal(err)
+			}
+			benchutil.BytesSink.Store(shared)
+		}
+	})
+}
+
+// checkMLKEM runs one exchange and compares the keys of both sides.
+func checkMLKEM[E encapsulationKey, D decapsulationKey[E]](t *testing.T, name string, generate func() (D, error)) {
+	dk, err := generate()
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	shared, ct := dk.EncapsulationKey().Encapsulate()
+	got, err := dk.Decapsulate(ct)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	if !bytes.Equal(got, shared) {
+		t.Errorf("%s: decapsulated key differs from the encapsulated one", name)
+	}
+}
+
+func TestKeyExchange(t *testing.T) {
+	for _, c := range ecdhCurves {
+		local, peer := ecdhPair(t, c.curve)
+		a, err := local.ECDH(peer.PublicKey())
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		b, err := peer.ECDH(local.PublicKey())
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if !bytes.Equal(a, b) {
+			t.Errorf("%s: both sides derive different secrets", c.name)
+		}
+	}
+	checkMLKEM[*mlkem.EncapsulationKey768](t, "MLKEM768", mlkem.GenerateKey768)
+	checkMLKEM[*mlkem.EncapsulationKey1024](t, "MLKEM1024", mlkem.GenerateKey1024)
+}
@@ -0,0 +1,157 @@
+package hash_crypto
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
+)
+
+// signAlgos are the signature schemes of TLS certificates and JWTs. They
+// all sign through crypto.Signer, Ed25519 the message itself and the
+// others a digest of it. RSA uses PKCS #1 v1.5, the RS256 of JWT.
+var signAlgos = []struct {
+	name     string
+	generate func() (crypto.Signer, error)
+	hash     crypto.Hash // digest signed, 0 signs the message
+	verify   func(pub crypto.PublicKey, digest, sig []byte) bool
+}{
+	{"Ed25519", func() (crypto.Signer, error) {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		return priv, err
+	}, 0, func(pub crypto.PublicKey, msg, sig []byte) bool {
+		return ed25519.Verify(pub.(ed25519.PublicKey), msg, sig)
+	}},
+	{"ECDSAP256", func() (crypto.Signer, error) {
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}, crypto.SHA256, verifyECDSA},
+	{"ECDSAP384", func() (crypto.Signer, error) {
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	}, crypto.SHA384, verifyECDSA},
+	{"RSA2048", func() (crypto.Signer, error) {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}, crypto.SHA256, verifyRSA(crypto.SHA256)},
+	{"RSA4096", func() (crypto.Signer, error) {
+		return rsa.GenerateKey(rand.Reader, 4096)
+	}, crypto.SHA256, verifyRSA(crypto.SHA256)},
+}
+
+func verifyECDSA(pub crypto.PublicKey, digest, sig []byte) bool {
+	return ecdsa.VerifyASN1(pub.(*ecdsa.PublicKey), digest, sig)
+}
+
+func verifyRSA(h crypto.Hash) func(crypto.PublicKey, []byte, []byte) bool {
+	return func(pub crypto.PublicKey, digest, sig []byte) bool {
+		return rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), h, digest, sig) == nil
+	}
+}
+
+// signed is the message of the signature benchmarks, a JWT sized payload.
+var signed = benchutil.Bytes(512)
+
+// digest returns what the algorithm signs for msg.
+func digest(h crypto.Hash, msg []byte) []byte {
+	switch h {
+	case crypto.SHA256:
+		d := sha256.Sum256(msg)
+		return d[:]
+	case crypto.SHA384:
+		d := sha512.Sum384(msg)
+		return d[:]
+	}
+	return msg
+}
+
+// signKeys caches one key per algorithm, RSA-4096 keys take seconds to
+// generate.
+var signKeys = make(map[string]crypto.Signer)
+
+func signKey(tb testing.TB, name string, generate func() (crypto.Signer, error)) crypto.Signer {
+	if key, ok := signKeys[name]; ok {
+		return key
+	}
+	key, err := generate()
+	if err != nil {
+		tb.Fatal(err)
+	}
+	signKeys[name] = key
+	return key
+}
+
+func BenchmarkSign(b *testing.B) {
+	for _, algo := range signAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			key := signKey(b, algo.name, algo.generate)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				sig, err := key.Sign(rand.Reader, digest(algo.hash, signed), algo.hash)
+				if err != nil {
+					b.Fatal(err)
+				}
+				benchutil.BytesSink.Store(sig)
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	for _, algo := range signAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			key := signKey(b, algo.name, algo.generate)
+			sig, err := key.Sign(rand.Reader, digest(algo.hash, signed), algo.hash)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !algo.verify(key.Public(), digest(algo.hash, signed), sig) {
+					b.Fatal("signature does not verify")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSignKeygen(b *testing.B) {
+	for _, algo := range signAlgos {
+		b.Run(algo.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				key, err := algo.generate()
+				if err != nil {
+					b.Fatal(err)
+				}
+				signerSink.Store(key)
+			}
+		})
+	}
+}
+
+var signerSink benchutil.Sink[crypto.Signer]
+
+func TestSignatures(t *testing.T) {
+	for _, algo := range signAlgos {
+		key := signKey(t, algo.name, algo.generate)
+		sig, err := key.Sign(rand.Reader, digest(algo.hash, signed), algo.hash)
+		if err != nil {
+			t.Fatalf("%s: %v", algo.name, err)
+		}
+		if !algo.verify(key.Public(), digest(algo.hash, signed), sig) {
+			t.Errorf("%s: signature does not verify", algo.name)
+		}
+		tampered := append([]byte(nil), signed...)
+		tampered[0] ^= 1
+		if algo.verify(key.Public(), digest(algo.hash, tampered), sig) {
+			t.Errorf("%s: signature verifies a modified message", algo.name)
+		}
+	}
+}
@@ -2,9 +2,10 @@
 package index
 
 import (
-	"math/rand"
 	"strconv"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 var NumItems int = 1000000
@@ -19,8 +20,8 @@ func initMapStringIndex() {
 	ms = make(map[string]string)
 	ks = make([]string, 0)
 
-	for i := 0; i < NumItems; i++ {
-		key := strconv.Itoa(rand.Intn(NumItems))
+	for i, k := range benchutil.Ints(NumItems, 0, NumItems) {
+		key := strconv.Itoa(k)
 		ms[key] = "value" + strconv.Itoa(i)
 		ks = append(ks, key)
 	}
@@ -30,8 +31,7 @@ func initMapIntIndex() {
 	mi = make(map[int]string)
 	ki = make([]int, 0)
 
-	for i := 0; i < NumItems; i++ {
-		key := rand.Intn(NumItems)
+	for i, key := range benchutil.Ints(NumItems, 0, NumItems) {
 		mi[key] = "value" + strconv.Itoa(i)
 		ki = append(ki, key)
 	}
@@ -74,7 +74,7 @@ func BenchmarkMapStringIndex(b *testing.B) {
 	i := 0
 
 	for n := 0; n < b.N; n++ {
-		_ = ms[ks[i]]
+		benchutil.StringSink.Store(ms[ks[i]])
 
 		i++
 		if i >= NumItems {
@@ -87,7 +87,7 @@ func BenchmarkMapIntIndex(b *testing.B) {
 	i := 0
 
 	for n := 0; n < b.N; n++ {
-		_ = mi[ki[i]]
+		benchutil.StringSink.Store(mi[ki[i]])
 
 		i++
 		if i >= NumItems {
@@ -0,0 +1,31 @@
+// Package benchutil holds the helpers shared by the benchmark packages:
+// typed result sinks, deterministic data generators and size sweeps.
+package benchutil
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Sizes runs fn as a sub-benchmark for every size. The sub-benchmarks are
+// named like "8B", "64KiB" or "8MiB", which benchrun draws as a sweep.
+func Sizes(b *testing.B, sizes []int, fn func(b *testing.B, n int)) {
+	for _, n := range sizes {
+		b.Run(SizeName(n), func(b *testing.B) {
+			fn(b, n)
+		})
+	}
+}
+
+// SizeName formats n bytes with the largest binary unit that divides it.
+func SizeName(n int) string {
+	for _, u := range []struct {
+		shift int
+		name  string
+	}{{30, "GiB"}, {20, "MiB"}, {10, "KiB"}} {
+		if n >= 1<<u.shift && n%(1<<u.shift) == 0 {
+			return fmt.Sprintf("%d%s", n>>u.shift, u.name)
+		}
+	}
+	return fmt.Sprintf("%dB", n)
+}
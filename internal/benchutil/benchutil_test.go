@@ -0,0 +1,116 @@
+package benchutil
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSizeName(t *testing.T) {
+	tests := map[int]string{
+		0:        "0B",
+		8:        "8B",
+		1000:     "1000B",
+		1 << 10:  "1KiB",
+		64 << 10: "64KiB",
+		1536:     "1536B",
+		8 << 20:  "8MiB",
+		1 << 30:  "1GiB",
+	}
+	for n, want := range tests {
+		if got := SizeName(n); got != want {
+			t.Errorf("SizeName(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestGeneratorsAreDeterministic(t *testing.T) {
+	if !bytes.Equal(Bytes(100), Bytes(100)) {
+		t.Error("Bytes differs between calls")
+	}
+	if ASCII(50) != ASCII(50) || Unicode(50) != Unicode(50) {
+		t.Error("strings differ between calls")
+	}
+	if !reflect.DeepEqual(Ints(20, -5, 5), Ints(20, -5, 5)) {
+		t.Error("Ints differs between calls")
+	}
+	if bytes.Equal(NewGen(1).Bytes(32), NewGen(2).Bytes(32)) {
+		t.Error("different seeds generate the same bytes")
+	}
+}
+
+func TestGenerators(t *testing.T) {
+	g := NewGen(Seed)
+	if b := g.Bytes(13); len(b) != 13 {
+		t.Errorf("len(Bytes(13)) = %d", len(b))
+	}
+	for _, c := range []byte(g.ASCII(1000)) {
+		if c < ' ' || c > '~' {
+			t.Fatalf("ASCII returned %q", c)
+		}
+	}
+	s := g.Unicode(1000)
+	if !utf8.ValidString(s) || utf8.RuneCountInString(s) != 1000 {
+		t.Errorf("Unicode(1000) has %d runes, valid %v", utf8.RuneCountInString(s), utf8.ValidString(s))
+	}
+	if len(s) <= 1000 {
+		t.Errorf("Unicode(1000) is %d bytes, want multi-byte runes", len(s))
+	}
+	for _, v := range g.Ints(1000, -3, 3) {
+		if v < -3 || v >= 3 {
+			t.Fatalf("Ints returned %d", v)
+		}
+	}
+}
+
+type record struct {
+	ID      int
+	Name    string
+	Score   float64
+	Tags    []string
+	Attrs   map[string]int
+	Next    *record
+	private int
+}
+
+func TestStructs(t *testing.T) {
+	rs := Structs[record](NewGen(Seed), 10)
+	if !reflect.DeepEqual(rs, Structs[record](NewGen(Seed), 10)) {
+		t.Error("Structs differs between generators with the same seed")
+	}
+	for _, r := range rs {
+		if r.Name == "" || r.Next == nil || r.private != 0 {
+			t.Errorf("badly filled record %+v", r)
+		}
+	}
+}
+
+func TestCloneGrid(t *testing.T) {
+	grid := [][]int{{1, 2}, {3}}
+	c := CloneGrid(grid)
+	c[0][0] = 9
+	if grid[0][0] != 1 || !reflect.DeepEqual(c, [][]int{{9, 2}, {3}}) {
+		t.Errorf("CloneGrid shares memory: %v %v", grid, c)
+	}
+}
+
+func TestFamily(t *testing.T) {
+	f := &Family[int, int]{
+		Reference: func(n int) int { return n * 2 },
+		Variants: []Variant[int, int]{
+			{Name: "Add", Fn: func(n int) int { return n + n }},
+			{Name: "Shift", Fn: func(n int) int { return n << 1 }},
+			{Name: "PositiveOnly", Fn: func(n int) int { return n * n / n * 2 }, Accepts: func(n int) bool { return n != 0 }},
+			{Name: "Wrong", Fn: func(n int) int { return n * 3 }},
+		},
+		Inputs: func(g *Gen) []int { return append(g.Ints(20, -100, 100), 0) },
+	}
+
+	f.verify()
+	for name, wantErr := range map[string]bool{"Add": false, "Shift": false, "PositiveOnly": false, "Wrong": true} {
+		if err := f.errors[name]; (err != nil) != wantErr {
+			t.Errorf("%s: error %v, want error %v", name, err, wantErr)
+		}
+	}
+}
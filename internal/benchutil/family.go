@@ -0,0 +1,136 @@
+package benchutil
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Family is a set of implementations of the same function. Before a
+// variant is timed its output is compared with the reference on the
+// check inputs, so a fast but wrong variant fails instead of winning.
+type Family[In, Out any] struct {
+	Reference func(In) Out
+	Variants  []Variant[In, Out]
+
+	// Inputs returns the inputs every variant is checked on, usually
+	// generated ones plus hand-written edge cases. It is called with a
+	// fresh generator for the reference and for every variant, so variants
+	// that modify their input in place cannot affect each other.
+	Inputs func(g *Gen) []In
+
+	// Equal compares two outputs, reflect.DeepEqual if nil.
+	Equal func(got, want Out) bool
+
+	once   sync.Once
+	errors map[string]error // verification result per variant
+	sink   Sink[Out]
+}
+
+// Variant is one implementation of a Family.
+type Variant[In, Out any] struct {
+	Name string
+	Fn   func(In) Out
+
+	// Accepts restricts the variant to the inputs it is specified for,
+	// such as strings.FieldsFunc, which drops empty fields, to inputs
+	// without them. Nil accepts every input.
+	Accepts func(In) bool
+}
+
+func (f *Family[In, Out]) accepts(v Variant[In, Out], in In) bool {
+	return v.Accepts == nil || v.Accepts(in)
+}
+
+// verify compares every variant with the reference on the check inputs,
+// once per family.
+func (f *Family[In, Out]) verify() {
+	f.once.Do(func() {
+		f.errors = make(map[string]error)
+		equal := f.Equal
+		if equal == nil {
+			equal = func(got, want Out) bool { return reflect.DeepEqual(got, want) }
+		}
+
+		var want []Out
+		for _, in := range f.Inputs(NewGen(Seed)) {
+			want = append(want, f.Reference(in))
+		}
+		for _, v := range f.Variants {
+			for i, in := range f.Inputs(NewGen(Seed)) {
+				if !f.accepts(v, in) {
+					continue
+				}
+				if got := v.Fn(in); !equal(got, want[i]) {
+					f.errors[v.Name] = fmt.Errorf("%s(%s) = %s, reference returns %s",
+						v.Name, short(in), short(got), short(want[i]))
+					break
+				}
+			}
+		}
+	})
+}
+
+// Verify reports every variant that disagrees with the reference, it is
+// meant to be called from a Test function of the family.
+func (f *Family[In, Out]) Verify(tb testing.TB) {
+	tb.Helper()
+	f.verify()
+	for _, v := range f.Variants {
+		if err := f.errors[v.Name]; err != nil {
+			tb.Error(err)
+		}
+	}
+}
+
+// Benchmark runs every variant as a sub-benchmark on the input in.
+func (f *Family[In, Out]) Benchmark(b *testing.B, in In) {
+	for _, v := range f.Variants {
+		b.Run(v.Name, func(b *testing.B) {
+			f.BenchmarkVariant(b, v.Name, in)
+		})
+	}
+}
+
+// BenchmarkVariant times the named variant on the input in. It fails
+// without timing if the variant disagrees with the reference or is not
+// specified for in.
+func (f *Family[In, Out]) BenchmarkVariant(b *testing.B, name string, in In) {
+	b.Helper()
+	f.verify()
+	v, ok := f.variant(name)
+	if !ok {
+		b.Fatalf("unknown variant %q", name)
+	}
+	if err := f.errors[name]; err != nil {
+		b.Fatal(err)
+	}
+	if !f.accepts(v, in) {
+		b.Fatalf("%s does not accept the benchmark input %s", name, short(in))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		f.sink.Store(v.Fn(in))
+	}
+}
+
+func (f *Family[In, Out]) variant(name string) (Variant[In, Out], bool) {
+	for _, v := range f.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Variant[In, Out]{}, false
+}
+
+// short formats v for an error message, cut to a readable length.
+func short(v any) string {
+	s := fmt.Sprintf("%#v", v)
+	if len(s) > 120 {
+		s = s[:117] + "..."
+	}
+	return s
+}
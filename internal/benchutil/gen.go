@@ -0,0 +1,172 @@
+package benchutil
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"strings"
+)
+
+// Seed is the seed of the package level generators. Every run benchmarks
+// the same inputs, so results of different runs stay comparable.
+const Seed = 0x5eed
+
+// Gen generates deterministic pseudo random benchmark inputs.
+type Gen struct {
+	r *rand.Rand
+}
+
+// NewGen returns a generator for the sequence of seed.
+func NewGen(seed uint64) *Gen {
+	return &Gen{r: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+}
+
+// Bytes returns n random bytes.
+func (g *Gen) Bytes(n int) []byte {
+	p := make([]byte, n)
+	for i := 0; i < n; i += 8 {
+		v := g.r.Uint64()
+		for j := i; j < n && j < i+8; j++ {
+			p[j] = byte(v)
+			v >>= 8
+		}
+	}
+	return p
+}
+
+// ASCII returns n random printable ASCII characters.
+func (g *Gen) ASCII(n int) string {
+	p := make([]byte, n)
+	for i := range p {
+		p[i] = byte(' ' + g.r.IntN('~'-' '+1))
+	}
+	return string(p)
+}
+
+// unicodeRanges are the scripts Unicode draws from, ASCII included so the
+// result has the mix of one to four byte sequences of real text.
+var unicodeRanges = []struct{ lo, hi rune }{
+	{'a', 'z'},
+	{'A', 'Z'},
+	{0x00c0, 0x00ff},   // Latin-1 letters such as ä and ß
+	{0x0391, 0x03c9},   // Greek
+	{0x0410, 0x044f},   // Cyrillic
+	{0x4e00, 0x4fff},   // CJK ideographs
+	{0x1f600, 0x1f64f}, // emoticons
+}
+
+// Unicode returns a string of n random runes of several scripts.
+func (g *Gen) Unicode(n int) string {
+	var sb strings.Builder
+	sb.Grow(n * 2)
+	for range n {
+		r := unicodeRanges[g.r.IntN(len(unicodeRanges))]
+		sb.WriteRune(r.lo + g.r.Int32N(r.hi-r.lo+1))
+	}
+	return sb.String()
+}
+
+// Int returns a random int in [lo, hi).
+func (g *Gen) Int(lo, hi int) int {
+	return lo + g.r.IntN(hi-lo)
+}
+
+// Ints returns n random ints in [lo, hi).
+func (g *Gen) Ints(n, lo, hi int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = g.Int(lo, hi)
+	}
+	return s
+}
+
+// Fill sets the exported fields of the struct ptr points to, recursively,
+// to random values: numbers, ASCII strings of 4 to 16 characters, and
+// slices and maps of up to 4 elements.
+func (g *Gen) Fill(ptr any) {
+	g.fill(reflect.ValueOf(ptr).Elem(), 0)
+}
+
+// maxFillDepth stops recursive types such as linked lists.
+const maxFillDepth = 4
+
+func (g *Gen) fill(v reflect.Value, depth int) {
+	if depth > maxFillDepth {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(g.r.IntN(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(g.r.Uint64() >> (64 - v.Type().Bits() + 1)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(g.r.Uint64() >> (64 - v.Type().Bits()))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(g.r.Float64() * 1000)
+	case reflect.String:
+		v.SetString(g.ASCII(g.Int(4, 17)))
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		g.fill(p.Elem(), depth+1)
+		v.Set(p)
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), g.r.IntN(5), 4)
+		for i := range s.Len() {
+			g.fill(s.Index(i), depth+1)
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := range v.Len() {
+			g.fill(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+		for range g.r.IntN(5) {
+			k := reflect.New(v.Type().Key()).Elem()
+			e := reflect.New(v.Type().Elem()).Elem()
+			g.fill(k, depth+1)
+			g.fill(e, depth+1)
+			m.SetMapIndex(k, e)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				g.fill(v.Field(i), depth+1)
+			}
+		}
+	}
+}
+
+// Structs returns n values of T filled by g.Fill.
+func Structs[T any](g *Gen, n int) []T {
+	s := make([]T, n)
+	for i := range s {
+		g.Fill(&s[i])
+	}
+	return s
+}
+
+// Bytes returns n random bytes, the same for every call with the same n.
+func Bytes(n int) []byte { return NewGen(Seed).Bytes(n) }
+
+// ASCII returns n random printable ASCII characters, the same for every
+// call with the same n.
+func ASCII(n int) string { return NewGen(Seed).ASCII(n) }
+
+// Unicode returns n random runes of several scripts, the same for every
+// call with the same n.
+func Unicode(n int) string { return NewGen(Seed).Unicode(n) }
+
+// Ints returns n random ints in [lo, hi), the same for every call with the
+// same arguments.
+func Ints(n, lo, hi int) []int { return NewGen(Seed).Ints(n, lo, hi) }
+
+// CloneGrid returns a deep copy of a two-dimensional slice, for benchmarks
+// of algorithms that modify their input in place.
+func CloneGrid[T any](grid [][]T) [][]T {
+	c := make([][]T, len(grid))
+	for i, row := range grid {
+		c[i] = append([]T(nil), row...)
+	}
+	return c
+}
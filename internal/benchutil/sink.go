@@ -0,0 +1,33 @@
+package benchutil
+
+// Sink keeps the result of a benchmarked call alive. Storing into a package
+// level Sink prevents the compiler from eliminating the call as dead code,
+// which a blank assignment such as `_ = f()` does not.
+//
+// A Sink is not safe for concurrent use; b.RunParallel bodies should
+// collect their results in a local variable and store it once.
+type Sink[T any] struct {
+	v T
+}
+
+// Store keeps v.
+func (s *Sink[T]) Store(v T) {
+	s.v = v
+}
+
+// Load returns the last stored value.
+func (s *Sink[T]) Load() T {
+	return s.v
+}
+
+// Sinks for the result types most benchmarks produce.
+var (
+	BoolSink    Sink[bool]
+	IntSink     Sink[int]
+	Int64Sink   Sink[int64]
+	Float64Sink Sink[float64]
+	StringSink  Sink[string]
+	StringsSink Sink[[]string]
+	BytesSink   Sink[[]byte]
+	AnySink     Sink[any]
+)
@@ -6,6 +6,8 @@ import (
 	"math/big"
 	"testing"
 	"time"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 type Data struct {
@@ -40,12 +42,13 @@ func BenchmarkJsonMarshal(b *testing.B) {
 			BigFloat: *big.NewFloat(math.MaxFloat64),
 		}
 
-		_, err := json.Marshal(d)
+		data, err := json.Marshal(d)
 		if err != nil {
 			b.Error(err)
 			b.Fail()
 			return
 		}
+		benchutil.BytesSink.Store(data)
 	}
 }
 
@@ -3,6 +3,8 @@ package json_compare
 import (
 	"encoding/json"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 type Payload struct {
@@ -21,7 +23,11 @@ var p = Payload{
 
 func BenchmarkStdlibMarshal(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		_, _ = json.Marshal(p)
+		data, err := json.Marshal(p)
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchutil.BytesSink.Store(data)
 	}
 }
 
@@ -30,6 +36,8 @@ func BenchmarkStdlibUnmarshal(b *testing.B) {
 	var out Payload
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = json.Unmarshal(data, &out)
+		if err := json.Unmarshal(data, &out); err != nil {
+			b.Fatal(err)
+		}
 	}
 }
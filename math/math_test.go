@@ -5,6 +5,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 func BenchmarkMathInt8(b *testing.B) {
@@ -12,6 +14,7 @@ func BenchmarkMathInt8(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		intVal = intVal + 2
 	}
+	benchutil.Int64Sink.Store(int64(intVal))
 }
 
 func BenchmarkMathInt32(b *testing.B) {
@@ -19,6 +22,7 @@ func BenchmarkMathInt32(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		intVal = intVal + 2
 	}
+	benchutil.Int64Sink.Store(int64(intVal))
 }
 
 func BenchmarkMathInt64(b *testing.B) {
@@ -26,6 +30,7 @@ func BenchmarkMathInt64(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		intVal = intVal + 2
 	}
+	benchutil.Int64Sink.Store(intVal)
 }
 
 func BenchmarkMathAtomicInt32(b *testing.B) {
@@ -61,6 +66,7 @@ func BenchmarkMathFloat32(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		floatVal = floatVal + 2
 	}
+	benchutil.Float64Sink.Store(float64(floatVal))
 }
 
 func BenchmarkMathFloat64(b *testing.B) {
@@ -68,4 +74,5 @@ func BenchmarkMathFloat64(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		floatVal = floatVal + 2
 	}
+	benchutil.Float64Sink.Store(floatVal)
 }
@@ -4,31 +4,36 @@ package parse
 import (
 	"strconv"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 func BenchmarkParseBool(b *testing.B) {
 	for n := 0; n < b.N; n++ {
-		_, err := strconv.ParseBool("true")
+		v, err := strconv.ParseBool("true")
 		if err != nil {
 			panic(err)
 		}
+		benchutil.BoolSink.Store(v)
 	}
 }
 
 func BenchmarkParseInt(b *testing.B) {
 	for n := 0; n < b.N; n++ {
-		_, err := strconv.ParseInt("1337", 10, 64)
+		v, err := strconv.ParseInt("1337", 10, 64)
 		if err != nil {
 			panic(err)
 		}
+		benchutil.Int64Sink.Store(v)
 	}
 }
 
 func BenchmarkParseFloat(b *testing.B) {
 	for n := 0; n < b.N; n++ {
-		_, err := strconv.ParseFloat("3.141592653589793238462643383", 64)
+		v, err := strconv.ParseFloat("3.141592653589793238462643383", 64)
 		if err != nil {
 			panic(err)
 		}
+		benchutil.Float64Sink.Store(v)
 	}
 }
@@ -10,15 +10,12 @@ import (
 	"math/big"
 	mrand "math/rand"
 	"testing"
-)
 
-var (
-	randomIntResult    int64
-	randomBigIntResult *big.Int
-	randomBytesResult  []byte
-	randomStringResult string
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
+var bigIntSink benchutil.Sink[*big.Int]
+
 type mathRandReader struct{}
 
 func (mathRandReader) Read(p []byte) (int, error) {
@@ -28,7 +25,7 @@ func (mathRandReader) Read(p []byte) (int, error) {
 func BenchmarkMathRand(b *testing.B) {
 	b.ReportAllocs()
 	for n := 0; n < b.N; n++ {
-		randomIntResult = mrand.Int63n(0xFFFF)
+		benchutil.Int64Sink.Store(mrand.Int63n(0xFFFF))
 	}
 }
 
@@ -41,7 +38,7 @@ func BenchmarkCryptoRand(b *testing.B) {
 		if err != nil {
 			panic(err)
 		}
-		randomBigIntResult = r
+		bigIntSink.Store(r)
 	}
 }
 
@@ -54,7 +51,7 @@ func BenchmarkMathRandBytes(b *testing.B) {
 		if err != nil {
 			panic(err)
 		}
-		randomBytesResult = r
+		benchutil.BytesSink.Store(r)
 	}
 }
 
@@ -65,7 +62,7 @@ func BenchmarkCryptoRandBytes(b *testing.B) {
 		if err != nil {
 			panic(err)
 		}
-		randomBytesResult = r
+		benchutil.BytesSink.Store(r)
 	}
 }
 
@@ -78,7 +75,7 @@ func BenchmarkMathRandString(b *testing.B) {
 		if err != nil {
 			panic(err)
 		}
-		randomStringResult = r
+		benchutil.StringSink.Store(r)
 	}
 }
 
@@ -89,7 +86,7 @@ func BenchmarkCryptoRandString(b *testing.B) {
 		if err != nil {
 			panic(err)
 		}
-		randomStringResult = r
+		benchutil.StringSink.Store(r)
 	}
 }
 
@@ -4,41 +4,67 @@ package split
 import (
 	"strings"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
-var splitResult []string
+func splitFieldsFunc(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' })
+}
 
-func BenchmarkSplitMethods(b *testing.B) {
-	input := "one,two,three,four,five"
-	comma := func(r rune) bool { return r == ',' }
+// noEmptyFields reports whether s has no empty fields, the only inputs
+// strings.FieldsFunc splits like strings.Split.
+func noEmptyFields(s string) bool {
+	return s != "" && !strings.Contains(s, ",,") && !strings.HasPrefix(s, ",") && !strings.HasSuffix(s, ",")
+}
 
-	b.Run("Strings.Split", func(b *testing.B) {
-		b.ReportAllocs()
-		for n := 0; n < b.N; n++ {
-			splitResult = strings.Split(input, ",")
-		}
-	})
+var splitFamily = &benchutil.Family[string, []string]{
+	Reference: func(s string) []string { return strings.Split(s, ",") },
+	Variants: []benchutil.Variant[string, []string]{
+		{Name: "Strings.Split", Fn: func(s string) []string { return strings.Split(s, ",") }},
+		{Name: "Strings.CutLoop", Fn: splitWithCut},
+		{Name: "Strings.FieldsFunc", Fn: splitFieldsFunc, Accepts: noEmptyFields},
+	},
+	Inputs: splitInputs,
+}
 
-	b.Run("Strings.SplitN", func(b *testing.B) {
-		b.ReportAllocs()
-		for n := 0; n < b.N; n++ {
-			splitResult = strings.SplitN(input, ",", 3)
+func splitInputs(g *benchutil.Gen) []string {
+	inputs := []string{"", ",", "a,,b", ",a,", "one,two,three,four,five"}
+	for range 100 {
+		fields := make([]string, g.Int(1, 10))
+		for i := range fields {
+			fields[i] = strings.ReplaceAll(g.ASCII(g.Int(0, 6)), ",", ";")
 		}
-	})
+		inputs = append(inputs, strings.Join(fields, ","))
+	}
+	return inputs
+}
 
-	b.Run("Strings.CutLoop", func(b *testing.B) {
-		b.ReportAllocs()
-		for n := 0; n < b.N; n++ {
-			splitResult = splitWithCut(input)
+// splitNFamily checks strings.SplitN with a limit of 3, the first two
+// fields and the rest unsplit, which the other variants do not compute.
+var splitNFamily = &benchutil.Family[string, []string]{
+	Reference: func(s string) []string {
+		parts := strings.Split(s, ",")
+		if len(parts) > 3 {
+			parts = append(parts[:2], strings.Join(parts[2:], ","))
 		}
-	})
+		return parts
+	},
+	Variants: []benchutil.Variant[string, []string]{
+		{Name: "Strings.SplitN", Fn: func(s string) []string { return strings.SplitN(s, ",", 3) }},
+	},
+	Inputs: splitInputs,
+}
 
-	b.Run("Strings.FieldsFunc", func(b *testing.B) {
-		b.ReportAllocs()
-		for n := 0; n < b.N; n++ {
-			splitResult = strings.FieldsFunc(input, comma)
-		}
-	})
+func TestSplitMethods(t *testing.T) {
+	splitFamily.Verify(t)
+	splitNFamily.Verify(t)
+}
+
+func BenchmarkSplitMethods(b *testing.B) {
+	const input = "one,two,three,four,five"
+	splitFamily.Benchmark(b, input)
+	splitNFamily.Benchmark(b, input)
 }
 
 func splitWithCut(s string) []string {
@@ -6,11 +6,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 	_ "modernc.org/sqlite"
 )
 
-var sqlResultSink int64
-
 func openSQLite(dsn string) (*sql.DB, error) {
 	return sql.Open("sqlite", dsn)
 }
@@ -189,7 +188,7 @@ func benchSelectPoint(b *testing.B, open func(string) (*sql.DB, error), dsn stri
 			b.Fatalf("query row: %v", err)
 		}
 		if active {
-			sqlResultSink += int64(len(email))
+			benchutil.Int64Sink.Store(int64(len(email)))
 		}
 	}
 }
@@ -228,7 +227,7 @@ func benchSelectRange(b *testing.B, open func(string) (*sql.DB, error), dsn stri
 		if err := rows.Close(); err != nil {
 			b.Fatalf("close rows: %v", err)
 		}
-		sqlResultSink += count
+		benchutil.Int64Sink.Store(count)
 	}
 }
 
@@ -246,7 +245,7 @@ func benchUpdatePrepared(b *testing.B, open func(string) (*sql.DB, error), dsn s
 		}
 		affected, err := result.RowsAffected()
 		if err == nil {
-			sqlResultSink += affected
+			benchutil.Int64Sink.Store(affected)
 		}
 	}
 }
@@ -266,7 +265,7 @@ func benchDeletePrepared(b *testing.B, open func(string) (*sql.DB, error), dsn s
 		}
 		affected, err := result.RowsAffected()
 		if err == nil {
-			sqlResultSink += affected
+			benchutil.Int64Sink.Store(affected)
 		}
 	}
 }
@@ -309,7 +308,7 @@ func benchAggregateByStatus(b *testing.B, open func(string) (*sql.DB, error), ds
 		if err := rows.Close(); err != nil {
 			b.Fatalf("close rows aggregate: %v", err)
 		}
-		sqlResultSink += total
+		benchutil.Int64Sink.Store(total)
 	}
 }
 
@@ -350,7 +349,7 @@ func benchSelectOrderedOrders(b *testing.B, open func(string) (*sql.DB, error),
 		if err := rows.Close(); err != nil {
 			b.Fatalf("close rows ordered orders: %v", err)
 		}
-		sqlResultSink += total
+		benchutil.Int64Sink.Store(total)
 	}
 }
 
@@ -394,7 +393,7 @@ func benchSelectJoin(b *testing.B, open func(string) (*sql.DB, error), dsn strin
 		if err := rows.Close(); err != nil {
 			b.Fatalf("close rows: %v", err)
 		}
-		sqlResultSink += totalCount
+		benchutil.Int64Sink.Store(totalCount)
 	}
 }
 
@@ -3,15 +3,21 @@ package sync_pool
 import (
 	"sync"
 	"testing"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 type Item struct {
 	buf [256]byte
 }
 
+// itemSink keeps the items of both benchmarks, so an item escapes to the
+// heap with and without the pool alike.
+var itemSink benchutil.Sink[*Item]
+
 func BenchmarkNewPerOp(b *testing.B) {
 	for i := 0; i < b.N; i++ {
-		_ = &Item{}
+		itemSink.Store(&Item{})
 	}
 }
 
@@ -20,6 +26,7 @@ func BenchmarkSyncPool(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		it := pool.Get().(*Item)
+		itemSink.Store(it)
 		pool.Put(it)
 	}
 }
@@ -3,10 +3,14 @@ package template
 
 import (
 	"bytes"
+	"fmt"
 	htmltemplate "html/template"
 	"regexp"
+	"strings"
 	"testing"
 	texttemplate "text/template"
+
+	"github.com/SimonWaldherr/golang-benchmarks/internal/benchutil"
 )
 
 // Define a struct to hold the data for the templates
@@ -24,33 +28,83 @@ var (
 	regExpString  = "Name: {{NAME}}, Address: {{ADDRESS}}"
 )
 
+var (
+	textTpl       = texttemplate.Must(texttemplate.New("text").Parse(textTplString))
+	htmlTpl       = htmltemplate.Must(htmltemplate.New("html").Parse(htmlTplString))
+	nameRegExp    = regexp.MustCompile(`{{NAME}}`)
+	addressRegExp = regexp.MustCompile(`{{ADDRESS}}`)
+)
+
+func renderText(d Data) string {
+	var buf bytes.Buffer
+	if err := textTpl.Execute(&buf, d); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
+func renderHTML(d Data) string {
+	var buf bytes.Buffer
+	if err := htmlTpl.Execute(&buf, d); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
+
+// renderRegExp replaces the placeholders literally, ReplaceAllString
+// would expand a $ in the data as a submatch reference.
+func renderRegExp(d Data) string {
+	result := nameRegExp.ReplaceAllLiteralString(regExpString, d.Name)
+	return addressRegExp.ReplaceAllLiteralString(result, d.Address)
+}
+
+func templateInputs(g *benchutil.Gen) []Data {
+	return append([]Data{data, {Name: "$1 & <b>", Address: `"O'Hara"`}}, benchutil.Structs[Data](g, 100)...)
+}
+
+// textFamily holds the variants that render textTplString.
+var textFamily = &benchutil.Family[Data, string]{
+	Reference: func(d Data) string { return fmt.Sprintf("Name: %s, Address: %s", d.Name, d.Address) },
+	Variants: []benchutil.Variant[Data, string]{
+		{Name: "TextTemplate", Fn: renderText},
+		{Name: "RegExp", Fn: renderRegExp},
+	},
+	Inputs: templateInputs,
+}
+
+// htmlEscaper escapes like html/template does in HTML text, which
+// unlike html.EscapeString includes the plus sign.
+var htmlEscaper = strings.NewReplacer(
+	"\x00", "\uFFFD", `"`, "&#34;", "'", "&#39;", "&", "&amp;", "+", "&#43;", "<", "&lt;", ">", "&gt;",
+)
+
+// htmlFamily holds html/template, which escapes the data.
+var htmlFamily = &benchutil.Family[Data, string]{
+	Reference: func(d Data) string {
+		return fmt.Sprintf("<div>Name: %s</div><div>Address: %s</div>", htmlEscaper.Replace(d.Name), htmlEscaper.Replace(d.Address))
+	},
+	Variants: []benchutil.Variant[Data, string]{
+		{Name: "HTMLTemplate", Fn: renderHTML},
+	},
+	Inputs: templateInputs,
+}
+
+func TestTemplates(t *testing.T) {
+	textFamily.Verify(t)
+	htmlFamily.Verify(t)
+}
+
 // Benchmark for text/template
 func BenchmarkTextTemplate(b *testing.B) {
-	tpl, _ := texttemplate.New("text").Parse(textTplString)
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		var buf bytes.Buffer
-		tpl.Execute(&buf, data)
-	}
+	textFamily.BenchmarkVariant(b, "TextTemplate", data)
 }
 
 // Benchmark for html/template
 func BenchmarkHTMLTemplate(b *testing.B) {
-	tpl, _ := htmltemplate.New("html").Parse(htmlTplString)
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		var buf bytes.Buffer
-		tpl.Execute(&buf, data)
-	}
+	htmlFamily.BenchmarkVariant(b, "HTMLTemplate", data)
 }
 
 // Benchmark for replacing placeholders using regexp
 func BenchmarkRegExp(b *testing.B) {
-	rName := regexp.MustCompile(`{{NAME}}`)
-	rAddress := regexp.MustCompile(`{{ADDRESS}}`)
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		result := rName.ReplaceAllString(regExpString, data.Name)
-		result = rAddress.ReplaceAllString(result, data.Address)
-	}
+	textFamily.BenchmarkVariant(b, "RegExp", data)
 }